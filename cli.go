@@ -0,0 +1,457 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"mcli/internal/api"
+	"mcli/internal/config"
+	"mcli/internal/output"
+	"mcli/internal/profile"
+	"mcli/internal/query"
+	"mcli/internal/source"
+	"mcli/internal/types"
+	"mcli/internal/utils"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// subcommands run once without a TTY, e.g. `mcli list --output json`
+var subcommands = map[string]func(args []string) error{
+	"list":      runList,
+	"show":      runShow,
+	"bookmarks": runBookmarks,
+	"bookmark":  runBookmark,
+	"fetch":     runFetch,
+	"export":    runExport,
+}
+
+// cliFlags are the flags shared by the subcommands
+type cliFlags struct {
+	config *config.Flags
+	user   string
+	output string
+	since  string
+	until  string
+	source string
+	query  string
+}
+
+// newFlagSet registers the config flags, --user and --output; withSelection
+// adds the flags choosing events
+func newFlagSet(name string, withSelection bool) (*flag.FlagSet, *cliFlags) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	f := &cliFlags{config: config.BindFlags(fs)}
+	fs.StringVar(&f.user, "user", "local", "Profile whose bookmarks and time zone are used")
+	fs.StringVar(&f.output, "output", output.Table, "Output format: "+strings.Join(output.Formats, ", "))
+	if withSelection {
+		fs.StringVar(&f.since, "since", "", "Only events from this date or time, or relative like -7d (default today)")
+		fs.StringVar(&f.until, "until", "", "Only events until this date (inclusive) or time, or relative like 7d")
+		fs.StringVar(&f.source, "source", "", "Only events from these sources, comma separated (luma, meetup, ical, file, ...)")
+		fs.StringVar(&f.query, "query", "", "Only events matching this filter, like / in the TUI")
+	}
+	return fs, f
+}
+
+// start parses args, then loads the config and the profile; the caller closes
+// the store. It returns the positional arguments, which flags may follow,
+// e.g. `mcli show <id> --output json`.
+func (f *cliFlags) start(fs *flag.FlagSet, args []string) (*profile.UserProfile, []string, error) {
+	var positional []string
+	for {
+		fs.Parse(args)
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	var err error
+	if f.output, err = output.ParseFormat(f.output); err != nil {
+		return nil, nil, err
+	}
+	if err := setup(f.config); err != nil {
+		return nil, nil, err
+	}
+	return loadProfile(f.user), positional, nil
+}
+
+// setup loads the config and opens the API client and profile store
+func setup(flags *config.Flags) error {
+	var err error
+	cfg, err = config.Load(flags)
+	if err != nil {
+		return err
+	}
+	client = newAPIClient(cfg)
+	store, err = profile.OpenStore(cfg.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open profile store: %w", err)
+	}
+	return nil
+}
+
+// loadProfile returns the user's profile, or an empty one for new users
+func loadProfile(userID string) *profile.UserProfile {
+	p, err := store.Load(userID)
+	if err != nil {
+		utils.Logger.Error("failed to load profile", "userID", userID, "err", err)
+		return profile.New(userID)
+	}
+	return p
+}
+
+// collectEvents fetches the events of every source enabled for p, sorted in
+// the profile's zone. Offline, or when no source answers, the cache is used.
+func collectEvents(ctx context.Context, p *profile.UserProfile) (types.Events, error) {
+	if !cfg.Offline {
+		var sources []source.EventSource
+		for _, spec := range source.Resolve(cfg.Sources, p.Sources) {
+			if !spec.Enabled {
+				continue
+			}
+			src, err := source.New(spec, client, client.HTTPClient)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "skipping source %s: %v\n", spec.Name, err)
+				continue
+			}
+			sources = append(sources, src)
+		}
+		fetched := source.Fetch(ctx, sources)
+		for _, err := range fetched.Errors {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		if len(fetched.Errors) < len(sources) {
+			return api.SortEvents(fetched.Events, p.Zone()), nil
+		}
+		if len(sources) > 0 {
+			fmt.Fprintln(os.Stderr, "no source reachable, using cached events")
+		}
+	}
+
+	cached, _, err := store.LoadEvents()
+	if err != nil {
+		return nil, err
+	}
+	return api.SortEvents(cached, p.Zone()), nil
+}
+
+// bookmarkedEvents returns the bookmarks of p: from events when they are
+// still listed, as they were when bookmarked otherwise (past events, sources
+// that were dropped)
+func bookmarkedEvents(events types.Events, p *profile.UserProfile) (types.Events, error) {
+	stored, err := store.BookmarkedEvents(p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	kept := p.Bookmarked(events)
+	for _, e := range stored {
+		if !slices.ContainsFunc(kept, func(c types.Event) bool { return c.ID == e.ID }) {
+			kept = append(kept, e)
+		}
+	}
+	return api.SortEvents(kept, p.Zone()), nil
+}
+
+// selectEvents applies --since, --until, --source and --query. Without
+// --since, events from before today are dropped unless past is set.
+func (f *cliFlags) selectEvents(events types.Events, zone *time.Location, past bool) (types.Events, error) {
+	now := time.Now()
+	var from time.Time // zero for no lower bound
+	if !past {
+		from = api.StartOfDay(now, zone)
+	}
+	if f.since != "" {
+		var err error
+		if from, err = parseBound(f.since, now, zone, false); err != nil {
+			return nil, fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+	var to time.Time
+	if f.until != "" {
+		var err error
+		if to, err = parseBound(f.until, now, zone, true); err != nil {
+			return nil, fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+
+	sources := map[string]bool{}
+	for _, s := range strings.Split(f.source, ",") {
+		if s = strings.ToLower(strings.TrimSpace(s)); s != "" {
+			sources[s] = true
+		}
+	}
+
+	var selected types.Events
+	for _, e := range events {
+		if len(sources) > 0 && !sources[strings.ToLower(e.Source)] {
+			continue
+		}
+		start, allDay, err := api.ParseDateTimeIn(e.DateTime, zone)
+		if err != nil {
+			// can't tell when, only kept when no range was asked for
+			if f.since == "" && f.until == "" {
+				selected = append(selected, e)
+			}
+			continue
+		}
+		// all-day events last until the end of their day
+		end := start
+		if allDay {
+			end = start.Add(24*time.Hour - time.Nanosecond)
+		}
+		if (!from.IsZero() && end.Before(from)) || (!to.IsZero() && !start.Before(to)) {
+			continue
+		}
+		selected = append(selected, e)
+	}
+	if f.query != "" {
+		q, err := query.Parse(f.query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --query: %w", err)
+		}
+		selected = q.Filter(selected, query.Env{Now: now, Zone: zone})
+	}
+	return selected, nil
+}
+
+// parseBound reads a --since/--until value: a date or date-time, now, today,
+// tomorrow or an offset such as 7d, -2d or 12h. An --until date includes that day.
+func parseBound(s string, now time.Time, zone *time.Location, until bool) (time.Time, error) {
+	switch strings.ToLower(s) {
+	case "now":
+		return now, nil
+	case "today":
+		s = "0d"
+	case "tomorrow":
+		s = "1d"
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			day := api.StartOfDay(now, zone).AddDate(0, 0, n)
+			if until {
+				day = day.AddDate(0, 0, 1)
+			}
+			return day, nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(d), nil
+	}
+	t, allDay, err := api.ParseDateTimeIn(s, zone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if allDay && until {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// runList implements `mcli list`
+func runList(args []string) error {
+	fs, f := newFlagSet("list", true)
+	p, _, err := f.start(fs, args)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	events, err := collectEvents(context.Background(), p)
+	if err != nil {
+		return err
+	}
+	if events, err = f.selectEvents(events, p.Zone(), false); err != nil {
+		return err
+	}
+	return output.Events(os.Stdout, f.output, events, p.Zone())
+}
+
+// runBookmarks implements `mcli bookmarks`; past bookmarks are listed too unless --since is given
+func runBookmarks(args []string) error {
+	fs, f := newFlagSet("bookmarks", true)
+	p, _, err := f.start(fs, args)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	events, err := collectEvents(context.Background(), p)
+	if err != nil {
+		return err
+	}
+	if events, err = bookmarkedEvents(events, p); err != nil {
+		return err
+	}
+	if events, err = f.selectEvents(events, p.Zone(), true); err != nil {
+		return err
+	}
+	return output.Events(os.Stdout, f.output, events, p.Zone())
+}
+
+// runShow implements `mcli show <id>`
+func runShow(args []string) error {
+	fs, f := newFlagSet("show", false)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mcli show [flags] <id>")
+		fs.PrintDefaults()
+	}
+	p, args, err := f.start(fs, args)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	if len(args) != 1 {
+		return errors.New("usage: mcli show [flags] <id>")
+	}
+
+	e, err := findEvent(p, types.EventId(args[0]))
+	if err != nil {
+		return err
+	}
+	return output.Event(os.Stdout, f.output, e, p.Zone())
+}
+
+// findEvent looks an event up by ID among all known events
+func findEvent(p *profile.UserProfile, id types.EventId) (types.Event, error) {
+	events, err := collectEvents(context.Background(), p)
+	if err != nil {
+		return types.Event{}, err
+	}
+	for _, e := range events {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return types.Event{}, fmt.Errorf("no event with ID %s", id)
+}
+
+// runBookmark implements `mcli bookmark add|rm <id>...`
+func runBookmark(args []string) error {
+	fs, f := newFlagSet("bookmark", false)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mcli bookmark [flags] add|rm <id>...")
+		fs.PrintDefaults()
+	}
+	p, args, err := f.start(fs, args)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	if len(args) < 2 || (args[0] != "add" && args[0] != "rm") {
+		return errors.New("usage: mcli bookmark [flags] add|rm <id>...")
+	}
+
+	action := args[0]
+	for _, arg := range args[1:] {
+		id := types.EventId(arg)
+		if action == "rm" {
+			if !p.IsBookmarked(id) {
+				return fmt.Errorf("%s is not bookmarked", id)
+			}
+			if err := store.RemoveBookmark(p.UserID, id); err != nil {
+				return fmt.Errorf("failed to remove bookmark: %w", err)
+			}
+			continue
+		}
+		// a mistyped ID would never show up anywhere
+		e, err := findEvent(p, id)
+		if err != nil {
+			return err
+		}
+		if err := store.AddBookmark(p.UserID, e); err != nil {
+			return fmt.Errorf("failed to add bookmark: %w", err)
+		}
+	}
+	return nil
+}
+
+// runFetch implements `mcli fetch <location>`, waiting for the scrape to finish
+func runFetch(args []string) error {
+	fs, f := newFlagSet("fetch", false)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mcli fetch [flags] [location]")
+		fs.PrintDefaults()
+	}
+	p, args, err := f.start(fs, args)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	location := strings.Join(args, " ")
+	if location == "" {
+		location = p.Location
+	}
+	if location == "" {
+		return errors.New("no location given or saved with :set-location")
+	}
+	if cfg.Offline {
+		return errors.New("offline mode, can't fetch new events")
+	}
+
+	ctx := context.Background()
+	job, err := client.TriggerFetch(ctx, location)
+	if err != nil {
+		return err
+	}
+	for !job.Done() {
+		time.Sleep(api.JobPollInterval)
+		updated, err := client.PollFetchJob(ctx, *job)
+		if err != nil {
+			return err
+		}
+		job = &updated
+	}
+	// the other formats report the status with the job
+	if f.output == output.Table {
+		switch job.Status {
+		case api.JobFailed:
+			return fmt.Errorf("fetch for %s failed: %s", location, job.Message)
+		case api.JobTimedOut:
+			return fmt.Errorf("fetch for %s: %s, %d new events so far", location, job.Message, job.NewEvents)
+		}
+	}
+	return output.Job(os.Stdout, f.output, *job)
+}
+
+// runExport implements `mcli export`
+func runExport(args []string) error {
+	fs, f := newFlagSet("export", true)
+	format := fs.String("format", "ics", "Export format, only ics is supported")
+	out := fs.String("out", "-", "File to write, - for stdout")
+	bookmarks := fs.Bool("bookmarks", false, "Export bookmarked events only")
+	past := fs.Bool("past", false, "Include events that already started, unless --since is given")
+	p, _, err := f.start(fs, args)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	if *format != "ics" {
+		return fmt.Errorf("unsupported export format %q, use ics", *format)
+	}
+
+	events, err := collectEvents(context.Background(), p)
+	if err != nil {
+		return err
+	}
+	if *bookmarks {
+		if events, err = bookmarkedEvents(events, p); err != nil {
+			return err
+		}
+	}
+	if events, err = f.selectEvents(events, p.Zone(), *past); err != nil {
+		return err
+	}
+	if err := exportICS(*out, events, p.Zone()); err != nil {
+		return err
+	}
+	if *out != "-" {
+		fmt.Fprintf(os.Stderr, "Exported %d events to %s\n", len(events), *out)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"mcli/internal/profile"
+	"mcli/internal/types"
+)
+
+// Kathmandu without tzdata; Wednesday 2026-10-14 at noon there
+var (
+	npt = time.FixedZone("NPT", 5*3600+45*60)
+	now = time.Date(2026, 10, 14, 12, 0, 0, 0, npt)
+)
+
+func TestParseBound(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, npt) }
+	tests := []struct {
+		in    string
+		until bool
+		want  time.Time
+	}{
+		{"now", false, now},
+		{"NOW", true, now},
+		{"today", false, day(14)},
+		{"today", true, day(15)},
+		{"tomorrow", false, day(15)},
+		{"tomorrow", true, day(16)},
+		{"7d", false, day(21)},
+		{"7d", true, day(22)},
+		{"-2d", false, day(12)},
+		{"0d", false, day(14)},
+		{"12h", false, now.Add(12 * time.Hour)},
+		{"-90m", true, now.Add(-90 * time.Minute)},
+		{"2026-10-24", false, day(24)},
+		{"2026-10-24", true, day(25)},
+		{"2026-10-24T18:30:00+05:45", false, time.Date(2026, 10, 24, 18, 30, 0, 0, npt)},
+		{"2026-10-24T18:30:00+05:45", true, time.Date(2026, 10, 24, 18, 30, 0, 0, npt)},
+		{"2026-10-24T12:00:00Z", false, time.Date(2026, 10, 24, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseBound(tt.in, now, npt, tt.until)
+		if err != nil {
+			t.Errorf("parseBound(%q, until=%v): %v", tt.in, tt.until, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseBound(%q, until=%v) = %v, want %v", tt.in, tt.until, got, tt.want)
+		}
+	}
+}
+
+func TestParseBoundErrors(t *testing.T) {
+	for _, in := range []string{"", "yesterday", "d", "7w", "2026-13-01", "next week"} {
+		if got, err := parseBound(in, now, npt, false); err == nil {
+			t.Errorf("parseBound(%q) = %v, want an error", in, got)
+		}
+	}
+}
+
+func TestSelectEvents(t *testing.T) {
+	// relative to the real clock, selectEvents has no other
+	today := time.Now().In(npt)
+	at := func(days int) string { return today.AddDate(0, 0, days).Format(time.RFC3339) }
+	date := func(days int) string { return today.AddDate(0, 0, days).Format("2006-01-02") }
+	events := types.Events{
+		{ID: "last-week", Source: "meetup", DateTime: at(-7)},
+		{ID: "earlier-today", Source: "luma", DateTime: date(0)},
+		{ID: "tomorrow", Title: "Rust Meetup", Source: "meetup", DateTime: at(1)},
+		{ID: "next-month", Source: "ical", DateTime: at(30)},
+		{ID: "mystery", DateTime: "soon"},
+	}
+	tests := []struct {
+		name string
+		f    cliFlags
+		past bool
+		want []types.EventId
+	}{
+		{"from today", cliFlags{}, false, []types.EventId{"earlier-today", "tomorrow", "next-month", "mystery"}},
+		{"past", cliFlags{}, true, []types.EventId{"last-week", "earlier-today", "tomorrow", "next-month", "mystery"}},
+		{"since wins over past", cliFlags{since: "1d"}, true, []types.EventId{"tomorrow", "next-month"}},
+		{"since", cliFlags{since: "-10d"}, false, []types.EventId{"last-week", "earlier-today", "tomorrow", "next-month"}},
+		{"until", cliFlags{until: "tomorrow"}, false, []types.EventId{"earlier-today", "tomorrow"}},
+		{"until with past", cliFlags{until: "today"}, true, []types.EventId{"last-week", "earlier-today"}},
+		{"source", cliFlags{source: "Meetup, ical"}, true, []types.EventId{"last-week", "tomorrow", "next-month"}},
+		{"query", cliFlags{query: "rust"}, false, []types.EventId{"tomorrow"}},
+	}
+	for _, tt := range tests {
+		got, err := tt.f.selectEvents(events, npt, tt.past)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		var ids []types.EventId
+		for _, e := range got {
+			ids = append(ids, e.ID)
+		}
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, ids, tt.want)
+		}
+	}
+}
+
+func TestSelectEventsErrors(t *testing.T) {
+	for _, f := range []cliFlags{{since: "someday"}, {until: "later"}, {query: "(rust"}} {
+		if _, err := f.selectEvents(nil, npt, false); err == nil {
+			t.Errorf("selectEvents(%+v) succeeded", f)
+		}
+	}
+}
+
+// a bookmark the sources no longer list is kept as it was bookmarked
+func TestBookmarkedEvents(t *testing.T) {
+	var err error
+	store, err = profile.OpenStore(filepath.Join(t.TempDir(), "mcli.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { store.Close(); store = nil }()
+
+	// the profile is created on first load
+	if _, err := store.Load("local"); err != nil {
+		t.Fatal(err)
+	}
+	gone := types.Event{ID: "gone", Title: "Last Week's Meetup", DateTime: "2026-10-07T12:15:00Z"}
+	listed := types.Event{ID: "listed", Title: "Rust Meetup", DateTime: "2026-10-21T12:15:00Z"}
+	for _, e := range []types.Event{gone, listed} {
+		if err := store.AddBookmark("local", e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p, err := store.Load("local")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the listed copy wins over the stored one
+	listed.Title = "Rust Meetup, moved"
+	events := types.Events{listed, {ID: "other", DateTime: "2026-10-22T12:15:00Z"}}
+	got, err := bookmarkedEvents(events, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "gone" || got[1].Title != listed.Title {
+		t.Errorf("got %v, want gone, then the listed copy", got)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"mcli/internal/calfeed"
+	"mcli/internal/ical"
+	"mcli/internal/source"
+	"mcli/internal/types"
+	"mcli/internal/utils"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// exportICS writes events as an iCalendar file at path, "-" for stdout
+func exportICS(path string, events types.Events, zone *time.Location) error {
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := ical.Write(w, events, time.Now(), zone); err != nil {
+		return fmt.Errorf("failed to write calendar: %w", err)
+	}
+	return nil
+}
+
+// handleExportCommand implements :export ics <file> [view|bookmarks]
+func (m *model) handleExportCommand(args []string) (string, tea.Cmd) {
+	const usage = "Usage: export ics <file> [view|bookmarks]"
+	if len(args) < 2 || len(args) > 3 || strings.ToLower(args[0]) != "ics" {
+		return usage, nil
+	}
+	// the file would be written on the server
+	if m.userID != "local" {
+		return "Export is only available when running mcli locally", nil
+	}
+
+	events := m.DisplayedEvents()
+	what := "view"
+	if len(args) == 3 {
+		what = strings.ToLower(args[2])
+	}
+	switch what {
+	case "view":
+	case "bookmarks":
+		events = m.profile.Bookmarked(m.Events)
+	default:
+		return usage, nil
+	}
+	if len(events) == 0 {
+		return "Nothing to export", nil
+	}
+
+	path := source.ExpandHome(args[1])
+	if err := exportICS(path, events, m.zone); err != nil {
+		return err.Error(), nil
+	}
+	return fmt.Sprintf("Exported %d events to %s", len(events), path), nil
+}
+
+// handleCalendarLinkCommand implements :calendar-link [new|revoke], the
+// subscription URL of this user's bookmarks
+func (m *model) handleCalendarLinkCommand(arg string) string {
+	if m.calendarURL == "" {
+		return "Calendar feeds are not enabled on this server"
+	}
+	// the link is tied to the SSH key, guests share one identity
+	if m.userID == "guest" {
+		return "Connect with an SSH key to get a calendar link"
+	}
+
+	token, err := m.store.CalendarToken(m.userID)
+	switch strings.ToLower(arg) {
+	case "":
+		if err == nil && token == "" {
+			token, err = m.store.NewCalendarToken(m.userID)
+		}
+	case "new":
+		token, err = m.store.NewCalendarToken(m.userID)
+	case "revoke":
+		if err == nil && token == "" {
+			return "No calendar link to revoke"
+		}
+		if err = m.store.RevokeCalendarToken(m.userID); err == nil {
+			return "Calendar link revoked, subscribed calendars will stop updating"
+		}
+	default:
+		return "Usage: calendar-link [new|revoke]"
+	}
+	if err != nil {
+		utils.Logger.Error("calendar link failed", "userID", m.userID, "err", err)
+		return "Failed to update calendar link"
+	}
+	return "Subscribe to your bookmarks: " + calfeed.URL(m.calendarURL, token)
+}
@@ -1,11 +1,7 @@
 package api
 
 import (
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-	"os"
+	"context"
 	"sort"
 	"time"
 
@@ -13,155 +9,145 @@ import (
 	"mcli/internal/utils"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/joho/godotenv"
 )
 
-// TODO: return config in future
-func LoadEnv() (string, error) {
-	err := godotenv.Load()
-	if err != nil {
-		return "", fmt.Errorf("Error loading .env file, %w", err)
-	}
-	apiBaseUrl := os.Getenv("API_BASE_URL")
-	if apiBaseUrl == "" {
-		return "", fmt.Errorf("API_BASE_URL not set in .env file")
-	}
-	return apiBaseUrl, nil
-
+type FetchErrorMsg struct {
+	Err error
 }
 
-func fetchEvents() ([]types.Event, error) {
-	apiBaseUrl, err := LoadEnv()
-	if err != nil {
-		return nil, err
-	}
-	apiUrl := apiBaseUrl + "/events"
-	utils.Logger.Info("Fetching events from", apiUrl, apiUrl)
-
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	resp, err := client.Get(apiUrl)
-	if err != nil {
-		return nil, fmt.Errorf("Failed to fetch events from %s, Error: %w", apiUrl, err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read events response body: %w", err)
-	}
-
-	var events []types.Event
-	if err := json.Unmarshal(body, &events); err != nil {
-		return nil, fmt.Errorf("Failed to parse response, %w", err)
-	}
-	return events, nil
+type FetchSuccessMsg struct {
+	Events     []types.Event
+	NextCursor string     // set when more pages are available
+	Query      EventQuery // what was fetched, so answers to older queries can be dropped
 }
 
-func fetchEventByLocation(location string) error {
-	apiBaseUrl, err := LoadEnv()
-	if err != nil {
-		return err
-	}
-	apiUrl := apiBaseUrl + "/fetch" + "?location=" + location
-	utils.Logger.Info("Fetching events for", location, location)
+// FetchPageMsg carries a further page requested with FetchNextPageCmd
+type FetchPageMsg struct {
+	Events     []types.Event
+	NextCursor string
+	Query      EventQuery // with the Cursor of this page
+}
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-	resp, err := client.Get(apiUrl)
-	if err != nil {
-		return fmt.Errorf("Failed to fetch events for %s, Error: %w", location, err)
-	}
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+// RetryMsg reports a failed attempt while FetchEventCmd keeps retrying.
+// The receiver must return Next to keep listening for the outcome.
+type RetryMsg struct {
+	RetryEvent
+	Next tea.Cmd
+}
 
-	if err != nil {
-		return fmt.Errorf("failed to read events response body: %w", err)
-	}
+// FetchEventCmd fetches and sorts the first page of events matching q;
+// ctx cancels the request when the UI quits. Retries are reported as
+// RetryMsg before the final FetchSuccessMsg or FetchErrorMsg.
+func (c *Client) FetchEventCmd(ctx context.Context, q EventQuery) tea.Cmd {
+	q.Cursor = ""
+	return c.fetchPageCmd(ctx, q, func(page EventPage) tea.Msg {
+		// sort events prior to returning, the model applies the user's window
+		return FetchSuccessMsg{Events: SortEvents(page.Events, time.Local), NextCursor: page.NextCursor, Query: q}
+	})
+}
 
-	// ugly grok shit
-	// Unmarshal the response into a map to avoid struct
-	var response map[string]string
-	if err := json.Unmarshal(body, &response); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
-	}
+// FetchNextPageCmd fetches the page of q at cursor, answering with FetchPageMsg
+func (c *Client) FetchNextPageCmd(ctx context.Context, q EventQuery, cursor string) tea.Cmd {
+	q.Cursor = cursor
+	return c.fetchPageCmd(ctx, q, func(page EventPage) tea.Msg {
+		return FetchPageMsg{Events: page.Events, NextCursor: page.NextCursor, Query: q}
+	})
+}
 
-	// Log the message field
-	if message, ok := response["message"]; ok {
-		utils.Logger.Info("Fetch response:", message)
-	} else {
-		return fmt.Errorf("response does not contain message field")
+func (c *Client) fetchPageCmd(ctx context.Context, q EventQuery, done func(EventPage) tea.Msg) tea.Cmd {
+	updates := make(chan tea.Msg, 1)
+	var next tea.Cmd
+	next = func() tea.Msg {
+		msg, ok := <-updates
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+
+	// nothing is requested until the program runs the command
+	return func() tea.Msg {
+		go func() {
+			defer close(updates)
+			send := func(msg tea.Msg) {
+				select {
+				case updates <- msg:
+				case <-ctx.Done():
+				}
+			}
+
+			observed := WithRetryObserver(ctx, func(ev RetryEvent) {
+				send(RetryMsg{RetryEvent: ev, Next: next})
+			})
+			page, err := c.ListEventsPage(observed, q)
+			if err != nil {
+				send(FetchErrorMsg{Err: err})
+				return
+			}
+			send(done(page))
+		}()
+		return next()
 	}
-
-	// TODO: reply user to refresh ui in a while
-	return nil
 }
 
-type FetchErrorMsg struct {
+// FetchJobMsg reports the progress of a scrape started by FetchEventByLocationCmd.
+// Err is set when the job could not be started or polled.
+type FetchJobMsg struct {
+	Job FetchJob
 	Err error
 }
 
-type FetchSuccessMsg struct {
-	Events []types.Event
-}
-
-func FetchEventCmd() tea.Msg {
-	events, err := fetchEvents()
-	if err != nil {
-		return FetchErrorMsg{Err: err}
+// FetchEventByLocationCmd asks the API to scrape events for location
+func (c *Client) FetchEventByLocationCmd(ctx context.Context, location string) tea.Cmd {
+	return func() tea.Msg {
+		job, err := c.TriggerFetch(ctx, location)
+		if err != nil {
+			return FetchJobMsg{Job: FetchJob{Location: location, Status: JobFailed}, Err: err}
+		}
+		return FetchJobMsg{Job: *job}
 	}
-	// sort events prior to returning
-	sortedEvents := sortByDate(events)
-	return FetchSuccessMsg{Events: sortedEvents}
 }
 
-func FetchEventByLocationCmd(location string) tea.Msg {
-	err := fetchEventByLocation(location)
-	if err != nil {
-		return FetchErrorMsg{Err: err}
-	}
-	return nil
+// PollFetchJobCmd checks on job after JobPollInterval
+func (c *Client) PollFetchJobCmd(ctx context.Context, job FetchJob) tea.Cmd {
+	return tea.Tick(JobPollInterval, func(time.Time) tea.Msg {
+		updated, err := c.PollFetchJob(ctx, job)
+		if err != nil {
+			updated.Status = JobFailed
+		}
+		return FetchJobMsg{Job: updated, Err: err}
+	})
 }
 
-// sortByDate sorts a slice of Events by DateTime in ascending order
-func sortByDate(events types.Events) types.Events {
-	// Get current date at midnight for comparison
-	currentDate := time.Now().Truncate(24 * time.Hour)
-
-	// Filter out events older than today
-	filteredEvents := types.Events{}
-	for _, event := range events {
-		eventTime, err := parseDateTime(event.DateTime)
+// SortEvents sorts events by DateTime in ascending order, reading dates
+// without a zone in loc. Events with a date that can't be parsed are kept at
+// the end so they can be flagged instead of silently disappearing.
+func SortEvents(events types.Events, loc *time.Location) types.Events {
+	type dated struct {
+		event types.Event
+		at    time.Time
+		ok    bool
+	}
+	kept := make([]dated, len(events))
+	for i, event := range events {
+		eventTime, _, err := ParseDateTimeIn(event.DateTime, loc)
 		if err != nil {
-			continue // Skip invalid dates
-		}
-		if !eventTime.Before(currentDate) {
-			filteredEvents = append(filteredEvents, event)
+			utils.Logger.Debug("unparseable event date", "id", event.ID, "source", event.Source, "value", event.DateTime, "err", err)
 		}
+		kept[i] = dated{event: event, at: eventTime, ok: err == nil}
 	}
 
-	// Sort filtered events in ascending order
-	sort.Slice(filteredEvents, func(i, j int) bool {
-		timeI, errI := parseDateTime(filteredEvents[i].DateTime)
-		timeJ, errJ := parseDateTime(filteredEvents[j].DateTime)
-
-		// Handle parsing errors: invalid dates go to the end
-		if errI != nil && errJ != nil {
-			return false
-		}
-		if errI != nil {
-			return false
-		}
-		if errJ != nil {
-			return true
+	// invalid dates go to the end, keeping their order
+	sort.SliceStable(kept, func(i, j int) bool {
+		if !kept[i].ok || !kept[j].ok {
+			return kept[i].ok && !kept[j].ok
 		}
-
-		// Sort by date in ascending order
-		return timeI.Before(timeJ)
+		return kept[i].at.Before(kept[j].at)
 	})
 
-	return filteredEvents
+	sorted := make(types.Events, len(kept))
+	for i, d := range kept {
+		sorted[i] = d.event
+	}
+	return sorted
 }
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned while the breaker is refusing requests
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// BreakerState is the state of a Breaker
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // requests flow normally
+	BreakerOpen                         // requests fail fast until the cooldown ends
+	BreakerHalfOpen                     // a single probe request is allowed through
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Breaker is a consecutive-failure circuit breaker. It is safe for concurrent
+// use, so one Breaker can protect the backend for every SSH session.
+type Breaker struct {
+	Threshold int           // consecutive failures that open the circuit
+	Cooldown  time.Duration // how long the circuit stays open
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewBreaker returns a closed Breaker
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{Threshold: threshold, Cooldown: cooldown}
+}
+
+// OpenError wraps ErrCircuitOpen with the time left before the next probe
+type OpenError struct {
+	RetryIn time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%v, retrying in %s", ErrCircuitOpen, e.RetryIn.Round(time.Second))
+}
+
+func (e *OpenError) Unwrap() error { return ErrCircuitOpen }
+
+// Allow reports whether a request may be sent, returning an *OpenError if not
+func (b *Breaker) Allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		left := b.Cooldown - time.Since(b.openedAt)
+		if left > 0 {
+			return &OpenError{RetryIn: left}
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return &OpenError{RetryIn: 0}
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Success records a successful request and closes the circuit
+func (b *Breaker) Success() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure records a failed request, opening the circuit at the threshold
+func (b *Breaker) Failure() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.state == BreakerHalfOpen || b.failures >= b.Threshold {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// Abort releases a probe whose outcome is unknown (e.g. the caller went away)
+func (b *Breaker) Abort() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// State returns the current state, moving to half-open once the cooldown is over
+func (b *Breaker) State() BreakerState {
+	if b == nil {
+		return BreakerClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.Cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	b := NewBreaker(2, 20*time.Millisecond)
+	allow := func(want bool) {
+		t.Helper()
+		err := b.Allow()
+		if got := err == nil; got != want {
+			t.Fatalf("Allow() = %v in state %v", err, b.State())
+		}
+		if err != nil && !errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Allow() = %v, want ErrCircuitOpen", err)
+		}
+	}
+	state := func(want BreakerState) {
+		t.Helper()
+		if got := b.State(); got != want {
+			t.Fatalf("State() = %v, want %v", got, want)
+		}
+	}
+
+	allow(true)
+	b.Failure()
+	state(BreakerClosed)
+	// a success in between starts the count over
+	b.Success()
+	b.Failure()
+	state(BreakerClosed)
+	b.Failure()
+	state(BreakerOpen)
+	allow(false)
+
+	// after the cooldown a single probe goes through
+	time.Sleep(b.Cooldown)
+	state(BreakerHalfOpen)
+	allow(true)
+	allow(false)
+	// a failed probe opens the circuit again
+	b.Failure()
+	state(BreakerOpen)
+
+	time.Sleep(b.Cooldown)
+	allow(true)
+	// a probe that was given up doesn't block the next one
+	b.Abort()
+	allow(true)
+	b.Success()
+	state(BreakerClosed)
+	allow(true)
+	allow(true)
+}
+
+func TestBreakerOpenError(t *testing.T) {
+	b := NewBreaker(1, time.Minute)
+	b.Failure()
+	var openErr *OpenError
+	if err := b.Allow(); !errors.As(err, &openErr) || openErr.RetryIn <= 0 || openErr.RetryIn > time.Minute {
+		t.Errorf("Allow() = %v, want an *OpenError within a minute", err)
+	}
+}
+
+// a nil Breaker lets everything through
+func TestBreakerNil(t *testing.T) {
+	var b *Breaker
+	b.Failure()
+	b.Failure()
+	if err := b.Allow(); err != nil || b.State() != BreakerClosed {
+		t.Errorf("nil breaker: %v, %v", err, b.State())
+	}
+}
+
+// the client stops calling a failing backend and probes it after the cooldown
+func TestClientBreaker(t *testing.T) {
+	var requests atomic.Int32
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	b := NewBreaker(2, 20*time.Millisecond)
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if s := int(status.Load()); s != http.StatusOK {
+			w.WriteHeader(s)
+			return
+		}
+		writeJSON(t, w, EventPage{})
+	}), WithBreaker(b))
+	ctx := context.Background()
+
+	// the retries of the first call open the circuit, the last one is refused
+	if _, err := c.ListEventsPage(ctx, EventQuery{}); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("got %v, want ErrCircuitOpen", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("%d requests, want 2", n)
+	}
+	if _, err := c.ListEventsPage(ctx, EventQuery{Text: "rust"}); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("got %v, want ErrCircuitOpen", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("%d requests while open, want 2", n)
+	}
+
+	status.Store(http.StatusOK)
+	time.Sleep(b.Cooldown)
+	if _, err := c.ListEventsPage(ctx, EventQuery{}); err != nil {
+		t.Fatal(err)
+	}
+	if b.State() != BreakerClosed {
+		t.Errorf("State() = %v after a good probe", b.State())
+	}
+}
+
+// client errors are the caller's fault, not the backend's
+func TestClientBreakerIgnoresClientErrors(t *testing.T) {
+	b := NewBreaker(1, time.Minute)
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}), WithBreaker(b))
+	for range 3 {
+		var apiErr *APIError
+		if _, err := c.ListEventsPage(context.Background(), EventQuery{}); !errors.As(err, &apiErr) {
+			t.Fatalf("got %v, want an *APIError", err)
+		}
+	}
+	if b.State() != BreakerClosed {
+		t.Errorf("State() = %v", b.State())
+	}
+}
@@ -0,0 +1,351 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"mcli/internal/types"
+	"mcli/internal/utils"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultUserAgent is sent with every request unless overridden
+const DefaultUserAgent = "mcli"
+
+// Client talks to the mcli.d API
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	UserAgent  string
+	Retry      RetryPolicy // applied to idempotent requests only
+	Breaker    *Breaker    // nil disables the circuit breaker
+
+	timeout time.Duration // set by WithTimeout on whichever HTTPClient ends up used
+
+	flight   singleflight.Group // collapses concurrent identical requests
+	flightMu sync.Mutex
+	calls    map[string]*flightCall // shared requests by key, with their waiting callers
+
+	// first pages of /events by query, with validators for conditional requests
+	eventsMu sync.Mutex
+	pages    map[string]cachedPage
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient replaces the underlying http.Client (e.g. for tests)
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.HTTPClient = hc }
+}
+
+// WithTimeout sets the timeout of the underlying http.Client, also one
+// given by WithHTTPClient in any order
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) { c.timeout = timeout }
+}
+
+// WithRetry sets the retry policy for idempotent requests
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Client) { c.Retry = p }
+}
+
+// WithBreaker protects the backend with b; share one Breaker between clients
+// (or use one Client) to make all callers back off together.
+func WithBreaker(b *Breaker) Option {
+	return func(c *Client) { c.Breaker = b }
+}
+
+// WithUserAgent sets the User-Agent header
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.UserAgent = ua }
+}
+
+// NewClient returns a Client for the API at baseURL
+func NewClient(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		UserAgent:  DefaultUserAgent,
+		Retry:      DefaultRetryPolicy,
+		pages:      map[string]cachedPage{},
+		calls:      map[string]*flightCall{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.timeout > 0 {
+		// a copy, the given client may be shared
+		hc := *c.HTTPClient
+		hc.Timeout = c.timeout
+		c.HTTPClient = &hc
+	}
+	return c
+}
+
+// response is the outcome of a successful (2xx or 304) request
+type response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// get performs a GET on endpoint with the extra request headers in h.
+// Idempotent requests are retried according to c.Retry.
+func (c *Client) get(ctx context.Context, endpoint string, query url.Values, h http.Header, idempotent bool) (*response, error) {
+	attempts := 1
+	if idempotent && c.Retry.MaxAttempts > 1 {
+		attempts = c.Retry.MaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, header, err := c.attempt(ctx, endpoint, query, h)
+		if err == nil || attempt >= attempts || !isRetryable(err) {
+			return resp, err
+		}
+
+		delay := c.Retry.Backoff(attempt)
+		if ra := retryAfter(header); ra > delay && ra <= c.Retry.MaxDelay {
+			delay = ra
+		}
+		utils.Logger.Info("retrying request", "endpoint", endpoint, "attempt", attempt, "delay", delay, "err", err)
+		notifyRetry(ctx, RetryEvent{Endpoint: endpoint, Attempt: attempt, MaxAttempts: attempts, Delay: delay, Err: err})
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt sends a single GET through the circuit breaker.
+// The response header is returned on errors too, for Retry-After.
+func (c *Client) attempt(ctx context.Context, endpoint string, query url.Values, h http.Header) (*response, http.Header, error) {
+	if err := c.Breaker.Allow(); err != nil {
+		return nil, nil, err
+	}
+
+	apiUrl := c.BaseURL + endpoint
+	if len(query) > 0 {
+		apiUrl += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiUrl, nil)
+	if err != nil {
+		c.Breaker.Abort()
+		return nil, nil, fmt.Errorf("failed to build request for %s: %w", endpoint, err)
+	}
+	for k, v := range h {
+		req.Header[k] = v
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		c.recordResult(ctx, err)
+		return nil, nil, fmt.Errorf("failed to reach %s: %w", apiUrl, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.recordResult(ctx, err)
+		return nil, resp.Header, fmt.Errorf("failed to read %s response body: %w", endpoint, err)
+	}
+
+	notModified := resp.StatusCode == http.StatusNotModified
+	if !notModified && (resp.StatusCode < 200 || resp.StatusCode > 299) {
+		apiErr := newAPIError(resp.StatusCode, endpoint, body)
+		c.recordResult(ctx, apiErr)
+		return nil, resp.Header, apiErr
+	}
+	c.Breaker.Success()
+	return &response{Status: resp.StatusCode, Header: resp.Header, Body: body}, resp.Header, nil
+}
+
+// recordResult feeds a failed attempt to the breaker: only transport errors
+// and temporary server errors count against the backend.
+func (c *Client) recordResult(ctx context.Context, err error) {
+	switch {
+	case ctx.Err() != nil:
+		c.Breaker.Abort()
+	case isRetryable(err):
+		c.Breaker.Failure()
+	default:
+		c.Breaker.Success()
+	}
+}
+
+// ListEvents returns all events known to the API, following page cursors.
+// The returned slice may be shared with other callers and must not be modified.
+func (c *Client) ListEvents(ctx context.Context) (types.Events, error) {
+	page, err := c.ListEventsPage(ctx, EventQuery{})
+	if err != nil || page.NextCursor == "" {
+		return page.Events, err
+	}
+
+	// copy before appending so the shared first page stays intact
+	events := append(types.Events{}, page.Events...)
+	q := EventQuery{Cursor: page.NextCursor}
+	for i := 1; q.Cursor != "" && i < maxPages; i++ {
+		page, err = c.ListEventsPage(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, page.Events...)
+		q.Cursor = page.NextCursor
+	}
+	return events, nil
+}
+
+// ListEventsPage returns the page of events matching q.
+// First pages are revalidated with ETag/Last-Modified, and concurrent calls
+// for the same query share one request, so the page must not be modified.
+func (c *Client) ListEventsPage(ctx context.Context, q EventQuery) (EventPage, error) {
+	key := "/events?" + q.Values().Encode()
+	call, w := c.join(ctx, key)
+	defer c.leave(key, call, w)
+	ch := c.flight.DoChan(key, func() (any, error) {
+		return c.listEvents(call.ctx, q)
+	})
+	select {
+	case <-ctx.Done():
+		return EventPage{}, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return EventPage{}, res.Err
+		}
+		return res.Val.(EventPage), nil
+	}
+}
+
+// flightCall is the context of a shared request. It outlives a caller that
+// goes away while others still wait, and is cancelled when none is left.
+type flightCall struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiters []*flightWaiter
+}
+
+// flightWaiter is a caller waiting for a shared request
+type flightWaiter struct {
+	observe func(RetryEvent) // from the caller's context, nil without one
+}
+
+// join registers a caller waiting for the shared request at key
+func (c *Client) join(ctx context.Context, key string) (*flightCall, *flightWaiter) {
+	c.flightMu.Lock()
+	defer c.flightMu.Unlock()
+	call, ok := c.calls[key]
+	if !ok {
+		call = &flightCall{}
+		// retries are reported to every caller waiting at the time
+		shared := WithRetryObserver(context.WithoutCancel(ctx), func(ev RetryEvent) {
+			c.flightMu.Lock()
+			waiters := slices.Clone(call.waiters)
+			c.flightMu.Unlock()
+			for _, w := range waiters {
+				if w.observe != nil {
+					w.observe(ev)
+				}
+			}
+		})
+		call.ctx, call.cancel = context.WithCancel(shared)
+		c.calls[key] = call
+	}
+	w := &flightWaiter{observe: retryObserver(ctx)}
+	call.waiters = append(call.waiters, w)
+	return call, w
+}
+
+// leave unregisters a caller of join, cancelling the request after the last one
+func (c *Client) leave(key string, call *flightCall, w *flightWaiter) {
+	c.flightMu.Lock()
+	defer c.flightMu.Unlock()
+	call.waiters = slices.DeleteFunc(call.waiters, func(o *flightWaiter) bool { return o == w })
+	if len(call.waiters) == 0 {
+		call.cancel()
+		delete(c.calls, key)
+		// later callers start over instead of joining a cancelled request
+		c.flight.Forget(key)
+	}
+}
+
+// cachedPage is a first page of /events with the validators it was served with
+type cachedPage struct {
+	page         EventPage
+	etag         string
+	lastModified string
+}
+
+func (c *Client) listEvents(ctx context.Context, q EventQuery) (EventPage, error) {
+	utils.Logger.Info("Fetching events", "base", c.BaseURL, "query", q.Values().Encode())
+
+	// only first pages are cached, cursors are one-off
+	key := q.Values().Encode()
+	cacheable := q.Cursor == ""
+	c.eventsMu.Lock()
+	cached, hit := c.pages[key]
+	c.eventsMu.Unlock()
+
+	h := http.Header{}
+	if cacheable && hit {
+		if cached.etag != "" {
+			h.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			h.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	resp, err := c.get(ctx, "/events", q.Values(), h, true)
+	if err != nil {
+		return EventPage{}, err
+	}
+	if resp.Status == http.StatusNotModified && hit {
+		utils.Logger.Info("Events not modified, using cached list")
+		return cached.page, nil
+	}
+
+	page, err := decodeEventPage(resp.Body)
+	if err != nil {
+		return EventPage{}, err
+	}
+
+	if cacheable {
+		c.eventsMu.Lock()
+		c.pages[key] = cachedPage{
+			page:         page,
+			etag:         resp.Header.Get("ETag"),
+			lastModified: resp.Header.Get("Last-Modified"),
+		}
+		c.eventsMu.Unlock()
+	}
+	return page, nil
+}
+
+// decodeEventPage accepts both a paged object and the older bare array
+func decodeEventPage(body []byte) (EventPage, error) {
+	var page EventPage
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &page.Events); err != nil {
+			return EventPage{}, &DecodeError{Endpoint: "/events", Err: err}
+		}
+	} else if err := json.Unmarshal(trimmed, &page); err != nil {
+		return EventPage{}, &DecodeError{Endpoint: "/events", Err: err}
+	}
+	if page.Events == nil {
+		page.Events = types.Events{}
+	}
+	return page, nil
+}
@@ -0,0 +1,330 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mcli/internal/types"
+	"mcli/internal/utils"
+)
+
+// fastRetry keeps retried tests quick
+var fastRetry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+// newTestClient serves h and returns a client for it
+func newTestClient(t *testing.T, h http.Handler, opts ...Option) *Client {
+	t.Helper()
+	utils.InitLogger(false)
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return NewClient(srv.URL, append([]Option{WithRetry(fastRetry)}, opts...)...)
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, v any) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Error(err)
+	}
+}
+
+func ids(events types.Events) []types.EventId {
+	var ids []types.EventId
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !cond(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+// waiters returns how many callers wait for the shared request at key
+func (c *Client) waiters(key string) int {
+	c.flightMu.Lock()
+	defer c.flightMu.Unlock()
+	if call, ok := c.calls[key]; ok {
+		return len(call.waiters)
+	}
+	return 0
+}
+
+func TestListEventsFollowsCursors(t *testing.T) {
+	pages := map[string]EventPage{
+		"":   {Events: types.Events{{ID: "1"}, {ID: "2"}}, NextCursor: "c2"},
+		"c2": {Events: types.Events{{ID: "3"}}, NextCursor: "c3"},
+		"c3": {Events: types.Events{{ID: "4"}}},
+	}
+	var cursors []string
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cursor := r.URL.Query().Get("cursor")
+		cursors = append(cursors, cursor)
+		writeJSON(t, w, pages[cursor])
+	}))
+
+	events, err := c.ListEvents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []types.EventId{"1", "2", "3", "4"}; !slices.Equal(ids(events), want) {
+		t.Errorf("got %v, want %v", ids(events), want)
+	}
+	if want := []string{"", "c2", "c3"}; !slices.Equal(cursors, want) {
+		t.Errorf("requested cursors %q, want %q", cursors, want)
+	}
+}
+
+// servers without paging answer with a bare array
+func TestListEventsPageBareArray(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, types.Events{{ID: "1"}, {ID: "2"}})
+	}))
+	page, err := c.ListEventsPage(context.Background(), EventQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Events) != 2 || page.NextCursor != "" {
+		t.Errorf("got %d events, cursor %q", len(page.Events), page.NextCursor)
+	}
+}
+
+func TestListEventsPageQuery(t *testing.T) {
+	var got string
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.RawQuery
+		writeJSON(t, w, EventPage{})
+	}))
+	q := EventQuery{
+		Location: "Kathmandu",
+		From:     time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC),
+		Source:   "luma",
+		Text:     "rust",
+		Limit:    20,
+		Cursor:   "c2",
+	}
+	if _, err := c.ListEventsPage(context.Background(), q); err != nil {
+		t.Fatal(err)
+	}
+	if want := "cursor=c2&from=2026-10-14T00%3A00%3A00Z&limit=20&location=Kathmandu&q=rust&source=luma"; got != want {
+		t.Errorf("query %q, want %q", got, want)
+	}
+}
+
+func TestListEventsPageNotModified(t *testing.T) {
+	var full, notModified atomic.Int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cursor") == "" && r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if r.URL.Query().Get("cursor") != "" && r.Header.Get("If-None-Match") != "" {
+			t.Error("a cursor page was revalidated")
+		}
+		full.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		writeJSON(t, w, EventPage{Events: types.Events{{ID: "1"}}, NextCursor: "c2"})
+	}))
+
+	ctx := context.Background()
+	first, err := c.ListEventsPage(ctx, EventQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := c.ListEventsPage(ctx, EventQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ids(again.Events), ids(first.Events)) || again.NextCursor != first.NextCursor {
+		t.Errorf("304 answered with %+v, want the cached %+v", again, first)
+	}
+	if _, err := c.ListEventsPage(ctx, EventQuery{Cursor: "c2"}); err != nil {
+		t.Fatal(err)
+	}
+	// another query has its own validators
+	if _, err := c.ListEventsPage(ctx, EventQuery{Text: "rust"}); err != nil {
+		t.Fatal(err)
+	}
+	if full.Load() != 3 || notModified.Load() != 1 {
+		t.Errorf("%d full and %d 304 responses, want 3 and 1", full.Load(), notModified.Load())
+	}
+}
+
+// concurrent callers of one query share a request
+func TestListEventsPageShared(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		writeJSON(t, w, EventPage{Events: types.Events{{ID: "1"}}})
+	}))
+
+	const callers = 3
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			page, err := c.ListEventsPage(context.Background(), EventQuery{})
+			if err == nil && len(page.Events) != 1 {
+				err = errors.New("missing events")
+			}
+			errs <- err
+		}()
+	}
+	waitFor(t, "every caller to join", func() bool { return c.waiters("/events?") == callers })
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("%d requests, want 1", n)
+	}
+	if n := c.waiters("/events?"); n != 0 {
+		t.Errorf("%d waiters left", n)
+	}
+}
+
+// a caller that goes away doesn't cancel the request the others wait for,
+// the last one does
+func TestListEventsPageSharedCancel(t *testing.T) {
+	release := make(chan struct{})
+	aborted := make(chan bool, 2)
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+			aborted <- false
+			writeJSON(t, w, EventPage{Events: types.Events{{ID: "1"}}})
+		case <-r.Context().Done():
+			aborted <- true
+		}
+	}))
+	key := "/events?"
+
+	leaving, cancel := context.WithCancel(context.Background())
+	left := make(chan error, 1)
+	go func() {
+		_, err := c.ListEventsPage(leaving, EventQuery{})
+		left <- err
+	}()
+	stayed := make(chan error, 1)
+	go func() {
+		page, err := c.ListEventsPage(context.Background(), EventQuery{})
+		if err == nil && len(page.Events) != 1 {
+			err = errors.New("missing events")
+		}
+		stayed <- err
+	}()
+	waitFor(t, "both callers to join", func() bool { return c.waiters(key) == 2 })
+
+	cancel()
+	if err := <-left; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled caller got %v", err)
+	}
+	close(release)
+	if err := <-stayed; err != nil {
+		t.Errorf("remaining caller got %v", err)
+	}
+	if <-aborted {
+		t.Error("the shared request was cancelled")
+	}
+
+	// the last caller going away cancels the request
+	release = make(chan struct{})
+	only, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.ListEventsPage(only, EventQuery{Text: "rust"})
+		done <- err
+	}()
+	waitFor(t, "the caller to join", func() bool { return c.waiters("/events?q=rust") == 1 })
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	if !<-aborted {
+		t.Error("the request outlived its last caller")
+	}
+}
+
+// retries of a shared request are reported to every caller
+func TestListEventsPageSharedRetries(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			<-release
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		writeJSON(t, w, EventPage{})
+	}))
+
+	const callers = 2
+	var mu sync.Mutex
+	retries := make([]int, callers)
+	var wg sync.WaitGroup
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := WithRetryObserver(context.Background(), func(RetryEvent) {
+				mu.Lock()
+				retries[i]++
+				mu.Unlock()
+			})
+			if _, err := c.ListEventsPage(ctx, EventQuery{}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	waitFor(t, "both callers to join", func() bool { return c.waiters("/events?") == callers })
+	close(release)
+	wg.Wait()
+	if want := []int{1, 1}; !slices.Equal(retries, want) {
+		t.Errorf("retries seen by each caller %v, want %v", retries, want)
+	}
+}
+
+// the message of a page carries its query, so the model can drop pages of
+// a list it has since refetched
+func TestFetchNextPageCmd(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, EventPage{Events: types.Events{{ID: types.EventId(r.URL.Query().Get("cursor"))}}, NextCursor: "c3"})
+	}))
+	q := EventQuery{Text: "rust", Limit: 20}
+	msg, ok := c.FetchNextPageCmd(context.Background(), q, "c2")().(FetchPageMsg)
+	if !ok {
+		t.Fatal("not a FetchPageMsg")
+	}
+	if msg.Query.Cursor != "c2" || !msg.Query.Same(q) || msg.NextCursor != "c3" {
+		t.Errorf("got query %+v, next %q", msg.Query, msg.NextCursor)
+	}
+	if len(msg.Events) != 1 || msg.Events[0].ID != "c2" {
+		t.Errorf("got %v, want the c2 page", ids(msg.Events))
+	}
+	if msg.Query.Same(EventQuery{Text: "go", Limit: 20}) {
+		t.Error("a page of another search counts as the same list")
+	}
+}
@@ -2,26 +2,102 @@ package api
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
-// Define date formats for parsing
-const (
-	formatWithOffset = "2006-01-02T15:04:05-07:00"
-	formatWithZ      = "2006-01-02T15:04:05.000Z"
-)
+// dateLayouts are tried in order. Layouts without a zone are read in the
+// zone named after the value (see ParseDateTime) or the local zone.
+var dateLayouts = []struct {
+	layout string
+	allDay bool // the value is a calendar date without a time of day
+}{
+	{layout: time.RFC3339Nano}, // also matches RFC3339 and the old "2006-01-02T15:04:05.000Z"
+	{layout: "2006-01-02T15:04:05Z0700"},
+	{layout: "2006-01-02T15:04Z07:00"},
+	{layout: "2006-01-02T15:04:05.999999999"},
+	{layout: "2006-01-02 15:04:05Z07:00"},
+	{layout: "2006-01-02 15:04:05"},
+	{layout: "2006-01-02T15:04"},
+	{layout: "2006-01-02 15:04"},
+	{layout: "20060102T150405Z"},
+	{layout: "20060102T150405"},
+	{layout: time.RFC1123Z},
+	{layout: time.RFC1123},
+	{layout: "2006-01-02", allDay: true},
+	{layout: "20060102", allDay: true},
+}
+
+// ParseDateTime parses the date-time formats backends send:
+//   - RFC3339 / ISO-8601 with or without fractional seconds and offset
+//   - a date alone, for all-day events (allDay is true, t is midnight)
+//   - a trailing IANA zone, "2025-05-10T18:00:00 Asia/Kathmandu" or "...[Asia/Kathmandu]"
+//   - Unix epoch seconds or milliseconds
+//
+// Values without an offset or zone are read in the local zone. The result is in UTC.
+func ParseDateTime(s string) (t time.Time, allDay bool, err error) {
+	return ParseDateTimeIn(s, time.Local)
+}
+
+// ParseDateTimeIn is ParseDateTime reading values without an offset or zone,
+// and dates of all-day events, in loc (nil for the local zone)
+func ParseDateTimeIn(s string, loc *time.Location) (t time.Time, allDay bool, err error) {
+	loc = zoneOrLocal(loc)
+	value := strings.TrimSpace(s)
+	if value == "" {
+		return time.Time{}, false, fmt.Errorf("empty date-time")
+	}
+	if t, ok := parseEpoch(value); ok {
+		return t, false, nil
+	}
 
-// parseDateTime parses a date-time string in supported formats
-func parseDateTime(dateTimeStr string) (time.Time, error) {
-	// Try parsing with offset format first
-	if t, err := time.Parse(formatWithOffset, dateTimeStr); err == nil {
-		return t.UTC(), nil
+	if v, zone, ok := splitZone(value); ok {
+		l, err := time.LoadLocation(zone)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("unknown time zone %q", zone)
+		}
+		value, loc = v, l
 	}
-	// Try parsing with UTC format
-	if t, err := time.Parse(formatWithZ, dateTimeStr); err == nil {
-		return t.UTC(), nil
+	for _, l := range dateLayouts {
+		if t, err := time.ParseInLocation(l.layout, value, loc); err == nil {
+			return t.UTC(), l.allDay, nil
+		}
 	}
-	return time.Time{}, fmt.Errorf("invalid date-time format")
+	return time.Time{}, false, fmt.Errorf("invalid date-time format %q", s)
+}
+
+// parseEpoch reads 10 digit seconds or 13 digit milliseconds since 1970
+func parseEpoch(s string) (time.Time, bool) {
+	if len(s) != 10 && len(s) != 13 {
+		return time.Time{}, false
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if len(s) == 13 {
+		return time.UnixMilli(n).UTC(), true
+	}
+	return time.Unix(n, 0).UTC(), true
+}
+
+// splitZone separates a trailing IANA zone name ("Area/City" or "UTC")
+func splitZone(s string) (value, zone string, ok bool) {
+	if strings.HasSuffix(s, "]") {
+		if i := strings.LastIndex(s, "["); i > 0 {
+			return strings.TrimSpace(s[:i]), s[i+1 : len(s)-1], true
+		}
+	}
+	i := strings.LastIndex(s, " ")
+	if i < 0 {
+		return s, "", false
+	}
+	zone = s[i+1:]
+	if !strings.Contains(zone, "/") && zone != "UTC" {
+		return s, "", false
+	}
+	return s[:i], zone, true
 }
 
 // getCurrentTimeUTC returns the current time in UTC
@@ -63,10 +139,11 @@ func formatDuration(duration time.Duration, isFutureOrCurrent bool) string {
 	return result
 }
 
-// ParseAndCompareDateTime parses a date-time string and compares it to now
-func ParseAndCompareDateTime(dateTimeStr string) (time.Time, bool, string, error) {
+// ParseAndCompareDateTime parses a date-time string, reading floating values
+// in loc, and compares it to now
+func ParseAndCompareDateTime(dateTimeStr string, loc *time.Location) (time.Time, bool, string, error) {
 	// Parse the date-time string
-	parsedTime, err := parseDateTime(dateTimeStr)
+	parsedTime, _, err := ParseDateTimeIn(dateTimeStr, loc)
 	if err != nil {
 		return time.Time{}, false, "", err
 	}
@@ -86,14 +163,20 @@ func ParseAndCompareDateTime(dateTimeStr string) (time.Time, bool, string, error
 	return parsedTime, isFutureOrCurrent, formatted, nil
 }
 
-// UTC2Local converts a UTC time to local time
-func UTC2Local(utcTime time.Time) time.Time {
-	// Get the local time zone
-	localZone, err := time.LoadLocation("Local")
-	if err != nil {
-		// Fallback to system local time if LoadLocation fails
-		return utcTime.Local()
+// InZone converts t to loc, or to the server's local zone when loc is nil
+func InZone(t time.Time, loc *time.Location) time.Time {
+	return t.In(zoneOrLocal(loc))
+}
+
+// StartOfDay returns midnight of t's day in loc
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(zoneOrLocal(loc)).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, zoneOrLocal(loc))
+}
+
+func zoneOrLocal(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.Local
 	}
-	// Convert UTC to local time
-	return utcTime.In(localZone)
+	return loc
 }
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBody caps how much of an error response is kept
+const maxErrorBody = 512
+
+// APIError is returned when mcli.d answers with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Endpoint   string
+	Body       string // raw response body, truncated to maxErrorBody
+	Message    string // error message reported by the server, if any
+}
+
+func newAPIError(status int, endpoint string, body []byte) *APIError {
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody]
+	}
+	return &APIError{
+		StatusCode: status,
+		Endpoint:   endpoint,
+		Body:       string(body),
+		Message:    serverMessage(body),
+	}
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s returned %d %s", e.Endpoint, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Temporary reports whether retrying the same request may succeed
+func (e *APIError) Temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusRequestTimeout
+}
+
+// serverMessage extracts a human readable message from an error body.
+// JSON bodies are searched for "error" or "message", HTML pages are ignored.
+func serverMessage(body []byte) string {
+	text := strings.TrimSpace(string(body))
+	if text == "" || strings.HasPrefix(text, "<") {
+		return ""
+	}
+
+	var payload struct {
+		Error   any    `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		switch v := payload.Error.(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case map[string]any:
+			if m, ok := v["message"].(string); ok && m != "" {
+				return m
+			}
+		}
+		return payload.Message
+	}
+
+	// plain text: keep the first line only
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	if len(text) > 200 {
+		text = text[:200] + "..."
+	}
+	return text
+}
+
+// DecodeError is returned when a 2xx response can't be parsed
+type DecodeError struct {
+	Endpoint string
+	Err      error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to parse %s response: %v", e.Endpoint, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error { return e.Err }
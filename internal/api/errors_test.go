@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	long := strings.Repeat("x", 2*maxErrorBody)
+	tests := []struct {
+		status    int
+		body      string
+		message   string
+		temporary bool
+	}{
+		{http.StatusNotFound, `{"error":"no such location"}`, "no such location", false},
+		{http.StatusBadRequest, `{"message":"invalid from"}`, "invalid from", false},
+		{http.StatusInternalServerError, `{"error":{"message":"database is down"}}`, "database is down", true},
+		{http.StatusBadGateway, "<html><body>Bad Gateway</body></html>", "", true},
+		{http.StatusTooManyRequests, "slow down\nretry later", "slow down", true},
+		{http.StatusRequestTimeout, "", "", true},
+		{http.StatusForbidden, long, long[:200] + "...", false},
+	}
+	for _, tt := range tests {
+		c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		}), WithRetry(RetryPolicy{}))
+
+		_, err := c.ListEventsPage(context.Background(), EventQuery{})
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Errorf("%d: got %v, want an *APIError", tt.status, err)
+			continue
+		}
+		if apiErr.StatusCode != tt.status || apiErr.Endpoint != "/events" {
+			t.Errorf("%d: got %d from %q", tt.status, apiErr.StatusCode, apiErr.Endpoint)
+		}
+		if apiErr.Message != tt.message {
+			t.Errorf("%d: Message = %q, want %q", tt.status, apiErr.Message, tt.message)
+		}
+		if len(apiErr.Body) > maxErrorBody {
+			t.Errorf("%d: kept %d bytes of the body", tt.status, len(apiErr.Body))
+		}
+		if apiErr.Temporary() != tt.temporary {
+			t.Errorf("%d: Temporary() = %v", tt.status, apiErr.Temporary())
+		}
+	}
+}
+
+func TestAPIErrorString(t *testing.T) {
+	err := newAPIError(http.StatusNotFound, "/events", []byte(`{"error":"no such location"}`))
+	if got, want := err.Error(), "/events returned 404 Not Found: no such location"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	err = newAPIError(http.StatusBadGateway, "/fetch", nil)
+	if got, want := err.Error(), "/fetch returned 502 Bad Gateway"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+// an unreadable body is a DecodeError and isn't asked for again
+func TestDecodeError(t *testing.T) {
+	var requests atomic.Int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte("<html>maintenance</html>"))
+	}))
+	_, err := c.ListEventsPage(context.Background(), EventQuery{})
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) || decodeErr.Endpoint != "/events" {
+		t.Fatalf("got %v, want a *DecodeError for /events", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("%d requests, want 1", n)
+	}
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"mcli/internal/types"
+	"mcli/internal/utils"
+)
+
+// Scrape job states reported by mcli.d
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+	// JobTimedOut is set by PollFetchJob on jobs that were followed too long
+	JobTimedOut = "timeout"
+)
+
+const (
+	// JobPollInterval is the pause between two progress checks of a FetchJob
+	JobPollInterval = 3 * time.Second
+	// jobMaxPolls bounds how long a job is followed (about 2 minutes)
+	jobMaxPolls = 40
+	// jobStablePolls is how many unchanged /events polls mark a job without an ID as done
+	jobStablePolls = 2
+)
+
+// FetchJob tracks a scrape started with TriggerFetch.
+// When mcli.d returns a job ID it is polled at /fetch/status, otherwise
+// /events is polled and new events are counted against a baseline.
+type FetchJob struct {
+	ID        string `json:"jobId"`
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	NewEvents int    `json:"newEvents"`
+
+	Location string `json:"-"`
+	Polls    int    `json:"-"`
+
+	known  map[types.EventId]bool // event IDs seen before the scrape started
+	stable int                    // consecutive polls without new events
+}
+
+// Done reports whether the job has finished, successfully or not, or is no
+// longer followed
+func (j *FetchJob) Done() bool {
+	return j.Status == JobDone || j.Status == JobFailed || j.Status == JobTimedOut
+}
+
+// TriggerFetch asks the API to scrape events for location and returns the started job
+func (c *Client) TriggerFetch(ctx context.Context, location string) (*FetchJob, error) {
+	utils.Logger.Info("Triggering fetch", "location", location)
+
+	// remember what exists now, in case the server can't report job progress
+	known := map[types.EventId]bool{}
+	if events, err := c.ListEvents(ctx); err == nil {
+		for _, e := range events {
+			known[e.ID] = true
+		}
+	}
+
+	resp, err := c.get(ctx, "/fetch", url.Values{"location": {location}}, nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &FetchJob{Location: location, known: known}
+	if err := json.Unmarshal(resp.Body, job); err != nil {
+		return nil, &DecodeError{Endpoint: "/fetch", Err: err}
+	}
+	if job.Message == "" && job.ID == "" && job.Status == "" {
+		return nil, &DecodeError{Endpoint: "/fetch", Err: fmt.Errorf("missing message field")}
+	}
+	if job.Status == "" {
+		job.Status = JobQueued
+	}
+	utils.Logger.Info("Fetch response", "message", job.Message, "job", job.ID, "status", job.Status)
+	return job, nil
+}
+
+// PollFetchJob checks the progress of job and returns its updated copy
+func (c *Client) PollFetchJob(ctx context.Context, job FetchJob) (FetchJob, error) {
+	job.Polls++
+	if job.ID != "" {
+		resp, err := c.get(ctx, "/fetch/status", url.Values{"id": {job.ID}}, nil, true)
+		if err != nil {
+			return job, err
+		}
+		status := job
+		if err := json.Unmarshal(resp.Body, &status); err != nil {
+			return job, &DecodeError{Endpoint: "/fetch/status", Err: err}
+		}
+		job = status
+	} else {
+		events, err := c.ListEvents(ctx)
+		if err != nil {
+			return job, err
+		}
+		found := 0
+		for _, e := range events {
+			if !job.known[e.ID] {
+				found++
+			}
+		}
+		// a scrape that finds nothing new is done once nothing changes either
+		if found > job.NewEvents {
+			job.NewEvents = found
+			job.stable = 0
+		} else {
+			job.stable++
+		}
+		job.Status = JobRunning
+		if job.stable >= jobStablePolls {
+			job.Status = JobDone
+		}
+	}
+
+	// stop following jobs that never finish
+	if !job.Done() && job.Polls >= jobMaxPolls {
+		job.Status = JobTimedOut
+		job.Message = "stopped waiting for the scrape to finish"
+	}
+	return job, nil
+}
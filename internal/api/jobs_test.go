@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"mcli/internal/types"
+)
+
+// jobServer answers /fetch with start, /fetch/status with the next of
+// statuses (the last one repeating) and /events with events
+type jobServer struct {
+	t        *testing.T
+	start    map[string]any
+	mu       sync.Mutex
+	statuses []map[string]any
+	events   types.Events
+}
+
+func (s *jobServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.URL.Path {
+	case "/fetch":
+		if r.URL.Query().Get("location") != "Kathmandu" {
+			s.t.Errorf("fetch for %q", r.URL.Query().Get("location"))
+		}
+		writeJSON(s.t, w, s.start)
+	case "/fetch/status":
+		if r.URL.Query().Get("id") != "job-1" {
+			s.t.Errorf("status of job %q", r.URL.Query().Get("id"))
+		}
+		status := s.statuses[0]
+		if len(s.statuses) > 1 {
+			s.statuses = s.statuses[1:]
+		}
+		writeJSON(s.t, w, status)
+	case "/events":
+		writeJSON(s.t, w, EventPage{Events: s.events})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *jobServer) setEvents(events ...types.EventId) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = nil
+	for _, id := range events {
+		s.events = append(s.events, types.Event{ID: id})
+	}
+}
+
+func TestPollFetchJob(t *testing.T) {
+	srv := &jobServer{
+		t:     t,
+		start: map[string]any{"jobId": "job-1", "status": "queued", "message": "scraping"},
+		statuses: []map[string]any{
+			{"jobId": "job-1", "status": "running", "newEvents": 1},
+			{"jobId": "job-1", "status": "done", "newEvents": 3, "message": "scraped 2 sites"},
+		},
+	}
+	c := newTestClient(t, srv)
+	ctx := context.Background()
+
+	job, err := c.TriggerFetch(ctx, "Kathmandu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.ID != "job-1" || job.Status != JobQueued || job.Location != "Kathmandu" {
+		t.Fatalf("started %+v", job)
+	}
+	polled, err := c.PollFetchJob(ctx, *job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if polled.Status != JobRunning || polled.NewEvents != 1 || polled.Done() {
+		t.Errorf("first poll: %+v", polled)
+	}
+	polled, err = c.PollFetchJob(ctx, polled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if polled.Status != JobDone || polled.NewEvents != 3 || polled.Message != "scraped 2 sites" || !polled.Done() {
+		t.Errorf("second poll: %+v", polled)
+	}
+	if polled.Location != "Kathmandu" || polled.Polls != 2 {
+		t.Errorf("lost the location or poll count: %+v", polled)
+	}
+}
+
+// without a job ID new events are counted until the list stops changing
+func TestPollFetchJobWithoutID(t *testing.T) {
+	srv := &jobServer{t: t, start: map[string]any{"message": "scraping Kathmandu"}}
+	srv.setEvents("old")
+	c := newTestClient(t, srv)
+	ctx := context.Background()
+
+	job, err := c.TriggerFetch(ctx, "Kathmandu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.ID != "" || job.Status != JobQueued {
+		t.Fatalf("started %+v", job)
+	}
+
+	steps := []struct {
+		events []types.EventId
+		found  int
+		status string
+	}{
+		{[]types.EventId{"old", "a"}, 1, JobRunning},
+		{[]types.EventId{"old", "a", "b"}, 2, JobRunning},
+		{[]types.EventId{"old", "a", "b"}, 2, JobRunning},
+		// a dropped event isn't a sign of progress either
+		{[]types.EventId{"a", "b"}, 2, JobDone},
+	}
+	for i, step := range steps {
+		srv.setEvents(step.events...)
+		updated, err := c.PollFetchJob(ctx, *job)
+		if err != nil {
+			t.Fatal(err)
+		}
+		job = &updated
+		if job.NewEvents != step.found || job.Status != step.status {
+			t.Errorf("poll %d: %d new, %s; want %d, %s", i+1, job.NewEvents, job.Status, step.found, step.status)
+		}
+	}
+}
+
+func TestPollFetchJobTimeout(t *testing.T) {
+	srv := &jobServer{
+		t:        t,
+		start:    map[string]any{"jobId": "job-1"},
+		statuses: []map[string]any{{"jobId": "job-1", "status": "running"}},
+	}
+	c := newTestClient(t, srv)
+	ctx := context.Background()
+
+	job, err := c.TriggerFetch(ctx, "Kathmandu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	polled := *job
+	for !polled.Done() {
+		if polled, err = c.PollFetchJob(ctx, polled); err != nil {
+			t.Fatal(err)
+		}
+		if polled.Polls > jobMaxPolls {
+			t.Fatalf("still polling after %d polls", polled.Polls)
+		}
+	}
+	if polled.Status != JobTimedOut || polled.Polls != jobMaxPolls || polled.Message == "" {
+		t.Errorf("got %+v, want a timed out job after %d polls", polled, jobMaxPolls)
+	}
+}
+
+func TestTriggerFetchErrors(t *testing.T) {
+	// nothing to follow
+	srv := &jobServer{t: t, start: map[string]any{}}
+	c := newTestClient(t, srv)
+	var decodeErr *DecodeError
+	if _, err := c.TriggerFetch(context.Background(), "Kathmandu"); !errors.As(err, &decodeErr) {
+		t.Errorf("got %v, want a *DecodeError", err)
+	}
+
+	// starting a scrape isn't idempotent, so it isn't retried
+	requests := 0
+	c = newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fetch" {
+			requests++
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		writeJSON(t, w, EventPage{})
+	}))
+	var apiErr *APIError
+	if _, err := c.TriggerFetch(context.Background(), "Kathmandu"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got %v, want a 503 *APIError", err)
+	}
+	if requests != 1 {
+		t.Errorf("%d /fetch requests, want 1", requests)
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"mcli/internal/types"
+)
+
+// EventQuery narrows /events on the server side. Zero fields are not sent.
+type EventQuery struct {
+	Location string
+	From     time.Time // events starting at or after From
+	To       time.Time // events starting before To
+	Source   string    // e.g. "luma"
+	Text     string    // free text search
+	Limit    int       // page size
+	Cursor   string    // opaque cursor from a previous EventPage
+}
+
+// Same reports whether q and o ask for the same events, whatever the page
+func (q EventQuery) Same(o EventQuery) bool {
+	q.Cursor, o.Cursor = "", ""
+	return q.Values().Encode() == o.Values().Encode()
+}
+
+// Unfiltered reports whether q asks for every upcoming event, which is
+// what the offline cache holds. A location narrows the list too, so one
+// location's events never replace everyone's.
+func (q EventQuery) Unfiltered() bool {
+	return q.Location == "" && q.To.IsZero() && q.Source == "" && q.Text == ""
+}
+
+// Values encodes the query as URL parameters
+func (q EventQuery) Values() url.Values {
+	v := url.Values{}
+	if q.Location != "" {
+		v.Set("location", q.Location)
+	}
+	if !q.From.IsZero() {
+		v.Set("from", q.From.UTC().Format(time.RFC3339))
+	}
+	if !q.To.IsZero() {
+		v.Set("to", q.To.UTC().Format(time.RFC3339))
+	}
+	if q.Source != "" {
+		v.Set("source", q.Source)
+	}
+	if q.Text != "" {
+		v.Set("q", q.Text)
+	}
+	if q.Limit > 0 {
+		v.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Cursor != "" {
+		v.Set("cursor", q.Cursor)
+	}
+	return v
+}
+
+// EventPage is one page of /events. NextCursor is empty on the last page.
+type EventPage struct {
+	Events     types.Events `json:"events"`
+	NextCursor string       `json:"nextCursor"`
+}
+
+// maxPages bounds ListEvents when following cursors
+const maxPages = 50
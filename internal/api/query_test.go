@@ -0,0 +1,49 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventQuerySame(t *testing.T) {
+	from := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	q := EventQuery{Location: "Kathmandu", From: from, Text: "rust", Limit: 20}
+	tests := []struct {
+		name string
+		o    EventQuery
+		want bool
+	}{
+		{"itself", q, true},
+		{"another page", EventQuery{Location: "Kathmandu", From: from, Text: "rust", Limit: 20, Cursor: "c2"}, true},
+		{"same instant elsewhere", EventQuery{Location: "Kathmandu", From: from.In(time.FixedZone("NPT", 5*3600+45*60)), Text: "rust", Limit: 20}, true},
+		{"another search", EventQuery{Location: "Kathmandu", From: from, Text: "go", Limit: 20}, false},
+		{"another window", EventQuery{Location: "Kathmandu", From: from.AddDate(0, 0, 1), Text: "rust", Limit: 20}, false},
+		{"another location", EventQuery{Location: "Pokhara", From: from, Text: "rust", Limit: 20}, false},
+		{"another page size", EventQuery{Location: "Kathmandu", From: from, Text: "rust"}, false},
+	}
+	for _, tt := range tests {
+		if got := q.Same(tt.o); got != tt.want {
+			t.Errorf("%s: Same = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEventQueryUnfiltered(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		q    EventQuery
+		want bool
+	}{
+		{EventQuery{}, true},
+		{EventQuery{From: now, Limit: 20, Cursor: "c2"}, true},
+		{EventQuery{To: now}, false},
+		{EventQuery{Source: "luma"}, false},
+		{EventQuery{Text: "rust"}, false},
+		{EventQuery{Location: "Kathmandu"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.q.Unfiltered(); got != tt.want {
+			t.Errorf("%+v: Unfiltered = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+}
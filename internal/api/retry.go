@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how idempotent requests are retried
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts including the first, <= 1 disables retries
+	BaseDelay   time.Duration // backoff before the second attempt
+	MaxDelay    time.Duration // upper bound for a single backoff
+}
+
+// DefaultRetryPolicy retries three times with 500ms, 1s, 2s (jittered) pauses
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+}
+
+// Backoff returns the jittered delay before attempt n+1 (n starts at 1).
+// It uses "equal jitter": half the exponential delay plus a random half.
+func (p RetryPolicy) Backoff(n int) time.Duration {
+	d := p.BaseDelay << (n - 1)
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + rand.N(half)
+}
+
+// RetryEvent describes a failed attempt that is about to be retried
+type RetryEvent struct {
+	Endpoint    string
+	Attempt     int // attempt that just failed, starting at 1
+	MaxAttempts int
+	Delay       time.Duration
+	Err         error
+}
+
+type retryObserverKey struct{}
+
+// WithRetryObserver returns a context that reports retries of requests made with it
+func WithRetryObserver(ctx context.Context, fn func(RetryEvent)) context.Context {
+	return context.WithValue(ctx, retryObserverKey{}, fn)
+}
+
+// retryObserver returns the observer set with WithRetryObserver, nil when there is none
+func retryObserver(ctx context.Context) func(RetryEvent) {
+	fn, _ := ctx.Value(retryObserverKey{}).(func(RetryEvent))
+	return fn
+}
+
+func notifyRetry(ctx context.Context, ev RetryEvent) {
+	if fn := retryObserver(ctx); fn != nil {
+		fn(ev)
+	}
+}
+
+// isRetryable reports whether err is worth another attempt
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	var decodeErr *DecodeError
+	// a body we can't parse won't get better by asking again
+	return !errors.As(err, &decodeErr)
+}
+
+// retryAfter parses a Retry-After header given in seconds
+func retryAfter(h http.Header) time.Duration {
+	secs, err := strconv.Atoi(h.Get("Retry-After"))
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
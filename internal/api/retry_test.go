@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// equal jitter: half the exponential delay, plus up to as much again
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for n, d := range map[int]time.Duration{
+		1:  100 * time.Millisecond,
+		2:  200 * time.Millisecond,
+		3:  400 * time.Millisecond,
+		4:  800 * time.Millisecond,
+		5:  time.Second,
+		40: time.Second, // the shift overflows
+	} {
+		for range 100 {
+			if got := p.Backoff(n); got < d/2 || got >= d {
+				t.Fatalf("Backoff(%d) = %v, want [%v, %v)", n, got, d/2, d)
+			}
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var requests atomic.Int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		writeJSON(t, w, EventPage{})
+	}))
+
+	var seen []RetryEvent
+	ctx := WithRetryObserver(context.Background(), func(ev RetryEvent) { seen = append(seen, ev) })
+	if _, err := c.ListEventsPage(ctx, EventQuery{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("%d requests, want 3", n)
+	}
+	if len(seen) != 2 {
+		t.Fatalf("observed %d retries, want 2", len(seen))
+	}
+	for i, ev := range seen {
+		var apiErr *APIError
+		if ev.Attempt != i+1 || ev.MaxAttempts != fastRetry.MaxAttempts || ev.Endpoint != "/events" || !errors.As(ev.Err, &apiErr) {
+			t.Errorf("retry %d: %+v", i+1, ev)
+		}
+		if ev.Delay > fastRetry.MaxDelay {
+			t.Errorf("retry %d waited %v, more than MaxDelay", i+1, ev.Delay)
+		}
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	tests := []struct {
+		status   int
+		requests int32
+	}{
+		{http.StatusInternalServerError, int32(fastRetry.MaxAttempts)},
+		{http.StatusTooManyRequests, int32(fastRetry.MaxAttempts)},
+		{http.StatusNotFound, 1},
+		{http.StatusUnauthorized, 1},
+	}
+	for _, tt := range tests {
+		var requests atomic.Int32
+		c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(tt.status)
+		}))
+		_, err := c.ListEventsPage(context.Background(), EventQuery{})
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+			t.Errorf("%d: got %v", tt.status, err)
+		}
+		if n := requests.Load(); n != tt.requests {
+			t.Errorf("%d: %d requests, want %d", tt.status, n, tt.requests)
+		}
+	}
+}
+
+// a cancelled context ends the backoff
+func TestRetryCancelled(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}), WithRetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Minute}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = WithRetryObserver(ctx, func(RetryEvent) { cancel() })
+	start := time.Now()
+	if _, err := c.ListEventsPage(ctx, EventQuery{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("returned after %v", d)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"":                              0,
+		"3":                             3 * time.Second,
+		"0":                             0,
+		"-1":                            0,
+		"Wed, 21 Oct 2026 07:28:00 GMT": 0, // dates aren't supported
+	} {
+		h := http.Header{}
+		if in != "" {
+			h.Set("Retry-After", in)
+		}
+		if got := retryAfter(h); got != want {
+			t.Errorf("retryAfter(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
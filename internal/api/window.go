@@ -0,0 +1,147 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Window presets
+const (
+	WindowUpcoming = "upcoming" // today onwards
+	WindowToday    = "today"
+	WindowWeekend  = "weekend" // this (or the coming) Saturday and Sunday
+	WindowWeek     = "week"    // today and the next 6 days
+	WindowMonth    = "month"   // today until the end of the month
+	WindowCustom   = "custom"  // From..To, both inclusive
+)
+
+// WindowPresets is the order the window key cycles through
+var WindowPresets = []string{WindowUpcoming, WindowToday, WindowWeekend, WindowWeek, WindowMonth}
+
+// Window is the span of days whose events are listed. Custom ranges are
+// kept as calendar dates so they mean the same days in any time zone.
+type Window struct {
+	Preset string
+	From   string // 2006-01-02, custom only
+	To     string // 2006-01-02, custom only
+}
+
+// ParseWindow reads a preset name or a custom range "2025-05-01..2025-05-10"
+// (a single date selects that day). The empty string is WindowUpcoming.
+func ParseWindow(s string) (Window, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "", "all", WindowUpcoming:
+		return Window{Preset: WindowUpcoming}, nil
+	case "7d", "next7d":
+		return Window{Preset: WindowWeek}, nil
+	}
+	for _, p := range WindowPresets {
+		if s == p {
+			return Window{Preset: p}, nil
+		}
+	}
+
+	from, to, found := strings.Cut(s, "..")
+	if !found {
+		from, to, found = strings.Cut(s, " ")
+	}
+	if !found {
+		to = from
+	}
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	f, err := time.Parse(dateOnly, from)
+	if err != nil {
+		return Window{}, fmt.Errorf("unknown window %q, use %s or YYYY-MM-DD..YYYY-MM-DD", s, strings.Join(WindowPresets, ", "))
+	}
+	t, err := time.Parse(dateOnly, to)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid end date %q, use YYYY-MM-DD", to)
+	}
+	if t.Before(f) {
+		return Window{}, fmt.Errorf("window ends (%s) before it starts (%s)", to, from)
+	}
+	return Window{Preset: WindowCustom, From: from, To: to}, nil
+}
+
+const dateOnly = "2006-01-02"
+
+// String is the form ParseWindow reads back, used to save the window
+func (w Window) String() string {
+	if w.Preset == WindowCustom {
+		return w.From + ".." + w.To
+	}
+	if w.Preset == "" {
+		return WindowUpcoming
+	}
+	return w.Preset
+}
+
+// Bounds returns the window as [from, to) on the day boundaries of loc.
+// to is zero for windows without an end.
+func (w Window) Bounds(now time.Time, loc *time.Location) (from, to time.Time) {
+	loc = zoneOrLocal(loc)
+	today := StartOfDay(now, loc)
+	day := func(t time.Time, days int) time.Time {
+		// AddDate keeps midnight across DST changes, Add(24h) doesn't
+		return t.AddDate(0, 0, days)
+	}
+
+	switch w.Preset {
+	case WindowToday:
+		return today, day(today, 1)
+	case WindowWeekend:
+		switch wd := today.Weekday(); wd {
+		case time.Saturday:
+			return today, day(today, 2)
+		case time.Sunday:
+			return today, day(today, 1)
+		default:
+			sat := day(today, int(time.Saturday-wd))
+			return sat, day(sat, 2)
+		}
+	case WindowWeek:
+		return today, day(today, 7)
+	case WindowMonth:
+		y, m, _ := today.Date()
+		return today, time.Date(y, m+1, 1, 0, 0, 0, 0, loc)
+	case WindowCustom:
+		f, _ := time.ParseInLocation(dateOnly, w.From, loc)
+		t, _ := time.ParseInLocation(dateOnly, w.To, loc)
+		return f, day(t, 1)
+	default:
+		return today, time.Time{}
+	}
+}
+
+// Next returns the preset after w in WindowPresets; custom ranges go back to the first
+func (w Window) Next() Window {
+	for i, p := range WindowPresets {
+		if p == w.Preset {
+			return Window{Preset: WindowPresets[(i+1)%len(WindowPresets)]}
+		}
+	}
+	return Window{Preset: WindowPresets[0]}
+}
+
+// Label describes the window for the status bar
+func (w Window) Label() string {
+	switch w.Preset {
+	case WindowToday:
+		return "today"
+	case WindowWeekend:
+		return "this weekend"
+	case WindowWeek:
+		return "next 7 days"
+	case WindowMonth:
+		return "this month"
+	case WindowCustom:
+		if w.From == w.To {
+			return w.From
+		}
+		return w.From + " – " + w.To
+	default:
+		return "upcoming"
+	}
+}
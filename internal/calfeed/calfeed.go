@@ -0,0 +1,107 @@
+package calfeed
+
+import (
+	"bytes"
+	"mcli/internal/api"
+	"mcli/internal/ical"
+	"mcli/internal/profile"
+	"mcli/internal/types"
+	"mcli/internal/utils"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Path is where feeds are served, followed by <token>.ics
+const Path = "/cal/"
+
+// Handler serves each user's bookmarks as an iCalendar feed at /cal/<token>.ics.
+// Tokens are created with profile.Store.NewCalendarToken.
+type Handler struct {
+	store *profile.Store
+}
+
+// New returns a feed handler reading tokens and bookmarks from store
+func New(store *profile.Store) *Handler {
+	return &Handler{store: store}
+}
+
+// URL returns the feed link for token under the public base URL
+func URL(base, token string) string {
+	return strings.TrimRight(base, "/") + Path + token + ".ics"
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	token, ok := strings.CutPrefix(r.URL.Path, Path)
+	if ok {
+		token, ok = strings.CutSuffix(token, ".ics")
+	}
+	if !ok || token == "" || strings.Contains(token, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	userID, err := h.store.CalendarUser(token)
+	if err != nil {
+		utils.Logger.Error("calendar feed lookup failed", "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if userID == "" {
+		// revoked or never issued
+		http.NotFound(w, r)
+		return
+	}
+	p, err := h.store.Load(userID)
+	if err != nil {
+		utils.Logger.Error("calendar feed profile failed", "userID", userID, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	bookmarked, err := h.bookmarked(p)
+	if err != nil {
+		utils.Logger.Error("calendar feed events failed", "userID", userID, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	events := api.SortEvents(bookmarked, p.Zone())
+	var buf bytes.Buffer
+	if err := ical.Write(&buf, events, time.Now(), p.Zone()); err != nil {
+		utils.Logger.Error("calendar feed write failed", "userID", userID, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	utils.Logger.Info("calendar feed served", "userID", userID, "events", len(events))
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="mcli.ics"`)
+	// calendar apps poll on their own schedule, keep shared caches out
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	http.ServeContent(w, r, "mcli.ics", time.Time{}, bytes.NewReader(buf.Bytes()))
+}
+
+// bookmarked returns the bookmarks of p: as last cached when they still are,
+// as they were when bookmarked otherwise (past events, other sources)
+func (h *Handler) bookmarked(p *profile.UserProfile) (types.Events, error) {
+	cached, _, err := h.store.LoadEvents()
+	if err != nil {
+		return nil, err
+	}
+	stored, err := h.store.BookmarkedEvents(p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	events := p.Bookmarked(cached)
+	for _, e := range stored {
+		if !slices.ContainsFunc(events, func(c types.Event) bool { return c.ID == e.ID }) {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,91 @@
+package columns
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Columns of the event table, see Parse
+const (
+	Icon     = "icon" // source, or · once read
+	Event    = "event"
+	Mark     = "mark" // ★ when bookmarked
+	Location = "location"
+	Price    = "price"
+	Distance = "distance" // only shown once the profile has a location
+	In       = "in"       // time until the event
+	Date     = "date"     // day and time
+	Rsvps    = "rsvps"
+	Venue    = "venue"
+	Source   = "source"
+	Status   = "status"
+	Type     = "type"
+)
+
+// Names lists the columns Parse accepts
+var Names = []string{Icon, Event, Mark, Location, Price, Distance, In, Date, Rsvps, Venue, Source, Status, Type}
+
+// Default is the layout when neither the config nor the profile set one
+const Default = "icon,event:3,mark,location:1,price,distance,in"
+
+// Flexible reports whether the column shares the width the fixed ones leave,
+// by the weight it is given
+func Flexible(name string) bool {
+	return name == Event || name == Location || name == Venue
+}
+
+// Column is a column of the layout; Weight sizes flexible columns
+type Column struct {
+	Name   string
+	Weight int
+}
+
+// Parse reads a comma or space separated list of columns in the order they
+// are shown, e.g. "icon,event:3,mark,venue,date". The flexible columns
+// (event, location, venue) take a relative width after a colon, 1 by
+// default. The event column is required; "" is Default.
+func Parse(s string) ([]Column, error) {
+	if strings.TrimSpace(s) == "" {
+		s = Default
+	}
+	var layout []Column
+	for _, field := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool { return r == ',' || r == ' ' }) {
+		name, weight, hasWeight := strings.Cut(field, ":")
+		if !slices.Contains(Names, name) {
+			return nil, fmt.Errorf("unknown column %q, use %s", name, strings.Join(Names, ", "))
+		}
+		if slices.ContainsFunc(layout, func(c Column) bool { return c.Name == name }) {
+			return nil, fmt.Errorf("column %q is listed twice", name)
+		}
+		c := Column{Name: name, Weight: 1}
+		if hasWeight {
+			if !Flexible(name) {
+				return nil, fmt.Errorf("column %q has a fixed width, only event, location and venue take a weight", name)
+			}
+			w, err := strconv.Atoi(weight)
+			if err != nil || w < 1 || w > 10 {
+				return nil, fmt.Errorf("invalid weight %q for %s, use 1 to 10", weight, name)
+			}
+			c.Weight = w
+		}
+		layout = append(layout, c)
+	}
+	if !slices.ContainsFunc(layout, func(c Column) bool { return c.Name == Event }) {
+		return nil, fmt.Errorf("the event column is required")
+	}
+	return layout, nil
+}
+
+// Format is the form Parse reads back, used to save the layout
+func Format(layout []Column) string {
+	fields := make([]string, len(layout))
+	for i, c := range layout {
+		fields[i] = c.Name
+		if Flexible(c.Name) && c.Weight != 1 {
+			fields[i] = fmt.Sprintf("%s:%d", c.Name, c.Weight)
+		}
+	}
+	return strings.Join(fields, ",")
+}
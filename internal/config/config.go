@@ -0,0 +1,371 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"mcli/internal/columns"
+	"mcli/internal/source"
+	"mcli/internal/types"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+)
+
+// Config holds the merged application settings.
+// Values are layered in this order: defaults, config file, environment, flags.
+type Config struct {
+	APIBaseURL  string            `toml:"api_base_url"`
+	HTTPTimeout Duration          `toml:"http_timeout"`
+	DBPath      string            `toml:"db_path"`
+	HostKeyPath string            `toml:"host_key_path"`
+	Theme       string            `toml:"theme"`
+	Keybindings map[string]string `toml:"keybindings"`
+	Offline     bool              `toml:"offline"` // only show cached events, never call the API
+	// table columns in order, e.g. ["icon", "event:3", "date"], see columns.Parse
+	Columns []string `toml:"columns"`
+
+	// retries of idempotent requests and the circuit breaker shared by all sessions
+	RetryAttempts    int      `toml:"retry_attempts"`
+	RetryBackoff     Duration `toml:"retry_backoff"`
+	BreakerThreshold int      `toml:"breaker_threshold"` // 0 disables the breaker
+	BreakerCooldown  Duration `toml:"breaker_cooldown"`
+
+	// how often the shared event pool refetches in wish mode
+	RefreshInterval Duration `toml:"refresh_interval"`
+	// events per /events page in the TUI, 0 loads everything at once
+	PageSize int `toml:"page_size"`
+
+	// wish mode: listen address of the bookmark calendar feeds, empty disables them,
+	// and the public URL the links are built from, required unless addr is a
+	// loopback address (then http://<addr>)
+	CalendarAddr string `toml:"calendar_addr"`
+	CalendarURL  string `toml:"calendar_url"`
+
+	// event providers, users may add their own on top of these
+	Sources []types.SourceSpec `toml:"sources"`
+	// hosts SSH users may add calendar feeds from, e.g. "*.google.com"; empty allows none
+	SourceHosts []string `toml:"source_hosts"`
+
+	// Keys is built from Keybindings by Validate
+	Keys Keymap `toml:"-"`
+	// path of the config file that was read, empty if none
+	File string `toml:"-"`
+}
+
+// Duration wraps time.Duration so it can be written as "10s" in TOML
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalText parses a duration string such as "10s" or "1m30s"
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+// Environment variables read by Load
+const (
+	EnvAPIBaseURL    = "MCLI_API_BASE_URL"
+	EnvHTTPTimeout   = "MCLI_HTTP_TIMEOUT"
+	EnvDBPath        = "MCLI_DB_PATH"
+	EnvHostKeyPath   = "MCLI_HOST_KEY_PATH"
+	EnvTheme         = "MCLI_THEME"
+	EnvCalendarAddr  = "MCLI_CALENDAR_ADDR"
+	EnvCalendarURL   = "MCLI_CALENDAR_URL"
+	legacyAPIBaseURL = "API_BASE_URL" // kept for existing .env files
+)
+
+// Default returns the built-in settings
+func Default() *Config {
+	return &Config{
+		HTTPTimeout: Duration{10 * time.Second},
+		DBPath:      "data/mcli.db",
+		HostKeyPath: ".ssh/events_app_ed25519",
+		Theme:       "default",
+		Keybindings: DefaultKeybindings(),
+
+		RetryAttempts:    4,
+		RetryBackoff:     Duration{500 * time.Millisecond},
+		BreakerThreshold: 5,
+		BreakerCooldown:  Duration{30 * time.Second},
+
+		RefreshInterval: Duration{5 * time.Minute},
+		PageSize:        100,
+
+		Sources: []types.SourceSpec{
+			{Name: source.KindMCLID, Kind: source.KindMCLID, Enabled: true},
+		},
+	}
+}
+
+// DefaultPath returns ~/.config/mcli/config.toml (or the XDG equivalent)
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "mcli", "config.toml")
+}
+
+// Flags holds the command-line overrides registered by BindFlags
+type Flags struct {
+	fs           *flag.FlagSet
+	configFile   string
+	apiBaseURL   string
+	httpTimeout  time.Duration
+	dbPath       string
+	hostKeyPath  string
+	theme        string
+	offline      bool
+	calendarAddr string
+}
+
+// BindFlags registers the config flags on fs; call Load after fs.Parse
+func BindFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{fs: fs}
+	fs.StringVar(&f.configFile, "config", DefaultPath(), "Path to config file")
+	fs.StringVar(&f.apiBaseURL, "api-url", "", "Base URL of the mcli.d API")
+	fs.DurationVar(&f.httpTimeout, "timeout", 0, "HTTP timeout for API requests")
+	fs.StringVar(&f.dbPath, "db", "", "Path to the SQLite database")
+	fs.StringVar(&f.hostKeyPath, "host-key", "", "Path to the SSH host key (wish mode)")
+	fs.StringVar(&f.theme, "theme", "", "Color theme")
+	fs.BoolVar(&f.offline, "offline", false, "Show cached events only, without contacting the API")
+	fs.StringVar(&f.calendarAddr, "calendar-addr", "", "Listen address of the bookmark calendar feeds (wish mode)")
+	return f
+}
+
+// Load merges defaults, the config file, environment variables and flags.
+// flags may be nil, in which case only the default config path is read.
+func Load(flags *Flags) (*Config, error) {
+	cfg := Default()
+
+	path := DefaultPath()
+	explicit := false
+	if flags != nil {
+		path = flags.configFile
+		explicit = flags.isSet("config")
+	}
+	if err := cfg.loadFile(path, explicit); err != nil {
+		return nil, err
+	}
+
+	// .env in the working directory is optional
+	_ = godotenv.Load()
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	if flags != nil {
+		flags.apply(cfg)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string, explicit bool) error {
+	if path == "" {
+		return nil
+	}
+	// keep defaults for keys missing from the file
+	keys := c.Keybindings
+	c.Keybindings = nil
+	_, err := toml.DecodeFile(path, c)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		c.Keybindings = keys
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("config: failed to read %s: %w", path, err)
+	}
+	for action, key := range c.Keybindings {
+		keys[action] = key
+	}
+	c.Keybindings = keys
+	c.File = path
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	if v := os.Getenv(legacyAPIBaseURL); v != "" {
+		c.APIBaseURL = v
+	}
+	if v := os.Getenv(EnvAPIBaseURL); v != "" {
+		c.APIBaseURL = v
+	}
+	if v := os.Getenv(EnvHTTPTimeout); v != "" {
+		if err := c.HTTPTimeout.UnmarshalText([]byte(v)); err != nil {
+			return fmt.Errorf("config: invalid %s %q: %w", EnvHTTPTimeout, v, err)
+		}
+	}
+	if v := os.Getenv(EnvDBPath); v != "" {
+		c.DBPath = v
+	}
+	if v := os.Getenv(EnvHostKeyPath); v != "" {
+		c.HostKeyPath = v
+	}
+	if v := os.Getenv(EnvTheme); v != "" {
+		c.Theme = v
+	}
+	if v := os.Getenv(EnvCalendarAddr); v != "" {
+		c.CalendarAddr = v
+	}
+	if v := os.Getenv(EnvCalendarURL); v != "" {
+		c.CalendarURL = v
+	}
+	return nil
+}
+
+func (f *Flags) isSet(name string) bool {
+	set := false
+	f.fs.Visit(func(fl *flag.Flag) {
+		if fl.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func (f *Flags) apply(c *Config) {
+	if f.isSet("api-url") {
+		c.APIBaseURL = f.apiBaseURL
+	}
+	if f.isSet("timeout") {
+		c.HTTPTimeout = Duration{f.httpTimeout}
+	}
+	if f.isSet("db") {
+		c.DBPath = f.dbPath
+	}
+	if f.isSet("host-key") {
+		c.HostKeyPath = f.hostKeyPath
+	}
+	if f.isSet("theme") {
+		c.Theme = f.theme
+	}
+	if f.isSet("offline") {
+		c.Offline = f.offline
+	}
+	if f.isSet("calendar-addr") {
+		c.CalendarAddr = f.calendarAddr
+	}
+}
+
+// Validate reports the first invalid setting
+func (c *Config) Validate() error {
+	for _, src := range c.Sources {
+		if err := source.Validate(src, false); err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+	}
+
+	// the API is not needed when running from the cache or from other sources
+	if c.APIBaseURL == "" && !c.Offline && c.usesMCLID() {
+		return fmt.Errorf("config: api_base_url is not set (use %s, --api-url or api_base_url in %s)", EnvAPIBaseURL, DefaultPath())
+	}
+	if c.APIBaseURL != "" {
+		u, err := url.Parse(c.APIBaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("config: invalid api_base_url %q: must be an http(s) URL", c.APIBaseURL)
+		}
+		c.APIBaseURL = strings.TrimRight(c.APIBaseURL, "/")
+	}
+
+	if c.HTTPTimeout.Duration <= 0 {
+		return fmt.Errorf("config: invalid http_timeout %s: must be positive", c.HTTPTimeout.Duration)
+	}
+	if c.RetryAttempts < 1 {
+		return fmt.Errorf("config: invalid retry_attempts %d: must be at least 1", c.RetryAttempts)
+	}
+	if c.RetryBackoff.Duration <= 0 {
+		return fmt.Errorf("config: invalid retry_backoff %s: must be positive", c.RetryBackoff.Duration)
+	}
+	if c.BreakerThreshold < 0 {
+		return fmt.Errorf("config: invalid breaker_threshold %d: must not be negative", c.BreakerThreshold)
+	}
+	if c.BreakerThreshold > 0 && c.BreakerCooldown.Duration <= 0 {
+		return fmt.Errorf("config: invalid breaker_cooldown %s: must be positive", c.BreakerCooldown.Duration)
+	}
+	if c.RefreshInterval.Duration < time.Second {
+		return fmt.Errorf("config: invalid refresh_interval %s: must be at least 1s", c.RefreshInterval.Duration)
+	}
+	if c.PageSize < 0 {
+		return fmt.Errorf("config: invalid page_size %d: must not be negative", c.PageSize)
+	}
+	if c.DBPath == "" {
+		return fmt.Errorf("config: db_path must not be empty")
+	}
+	if c.HostKeyPath == "" {
+		return fmt.Errorf("config: host_key_path must not be empty")
+	}
+	if c.CalendarAddr != "" && c.CalendarURL == "" && !isLoopback(c.CalendarAddr) {
+		return fmt.Errorf("config: calendar_url is required to serve calendar feeds on %q", c.CalendarAddr)
+	}
+	if c.CalendarURL != "" {
+		u, err := url.Parse(c.CalendarURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("config: invalid calendar_url %q: must be an http(s) URL", c.CalendarURL)
+		}
+		c.CalendarURL = strings.TrimRight(c.CalendarURL, "/")
+	}
+	if _, err := columns.Parse(strings.Join(c.Columns, ",")); err != nil {
+		return fmt.Errorf("config: invalid columns: %w", err)
+	}
+	if !isKnownTheme(c.Theme) {
+		return fmt.Errorf("config: unknown theme %q (available: %s)", c.Theme, strings.Join(Themes, ", "))
+	}
+	keys, err := NewKeymap(c.Keybindings)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	c.Keys = keys
+	return nil
+}
+
+// usesMCLID reports whether an enabled source needs the mcli.d API
+func (c *Config) usesMCLID() bool {
+	for _, src := range c.Sources {
+		if src.Enabled && src.Kind == source.KindMCLID {
+			return true
+		}
+	}
+	return false
+}
+
+// Themes lists the theme names accepted by the theme setting
+var Themes = []string{"default", "light"}
+
+func isKnownTheme(name string) bool {
+	for _, t := range Themes {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isLoopback reports whether the listen address addr only accepts local connections
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
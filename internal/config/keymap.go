@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Actions that can be bound to a key
+const (
+	ActionQuit     = "quit"
+	ActionDetails  = "details"
+	ActionFilter   = "filter"
+	ActionRefresh  = "refresh"
+	ActionBookmark = "bookmark"
+	ActionOpen     = "open"
+	ActionNext     = "next"
+	ActionPrev     = "prev"
+	ActionWindow   = "window"   // cycle the time window presets
+	ActionPast     = "past"     // show or hide past events
+	ActionAgenda   = "agenda"   // switch between the table and the agenda
+	ActionCalendar = "calendar" // switch between the table and the month grid
+	ActionSort     = "sort"     // cycle the table's sort key
+	ActionReverse  = "reverse"  // flip the table's sort order
+)
+
+// Actions of the month grid; their keys may also be bound to an action above,
+// which the calendar view then shadows
+const (
+	ActionLeft  = "left"  // the day before
+	ActionRight = "right" // the day after
+	ActionUp    = "up"    // a week back
+	ActionDown  = "down"  // a week ahead
+)
+
+// calendarActions are the actions only the calendar view uses
+var calendarActions = map[string]bool{ActionLeft: true, ActionRight: true, ActionUp: true, ActionDown: true}
+
+// DefaultKeybindings maps each action to its default key
+func DefaultKeybindings() map[string]string {
+	return map[string]string{
+		ActionQuit:     "q",
+		ActionDetails:  "y",
+		ActionFilter:   "/",
+		ActionRefresh:  "r",
+		ActionBookmark: "b",
+		ActionOpen:     "o",
+		ActionNext:     "h",
+		ActionPrev:     "l",
+		ActionWindow:   "w",
+		ActionPast:     "p",
+		ActionAgenda:   "v",
+		ActionCalendar: "c",
+		ActionSort:     "s",
+		ActionReverse:  "S",
+
+		ActionLeft:  "h",
+		ActionRight: "l",
+		ActionUp:    "k",
+		ActionDown:  "j",
+	}
+}
+
+// Keymap resolves a pressed key to its bound action
+type Keymap struct {
+	actions  map[string]string // key -> action
+	calendar map[string]string // key -> calendar action
+	keys     map[string]string // action -> key
+}
+
+// NewKeymap builds a Keymap from action -> key bindings,
+// rejecting unknown actions, empty keys and keys bound twice in the same view.
+func NewKeymap(bindings map[string]string) (Keymap, error) {
+	defaults := DefaultKeybindings()
+	km := Keymap{actions: map[string]string{}, calendar: map[string]string{}, keys: map[string]string{}}
+
+	// iterate in a stable order so errors are deterministic
+	names := make([]string, 0, len(bindings))
+	for action := range bindings {
+		names = append(names, action)
+	}
+	sort.Strings(names)
+
+	for _, action := range names {
+		key := bindings[action]
+		if _, ok := defaults[action]; !ok {
+			return Keymap{}, fmt.Errorf("unknown keybinding action %q", action)
+		}
+		if key == "" {
+			return Keymap{}, fmt.Errorf("keybinding for %q is empty", action)
+		}
+		if key == ":" || key == "ctrl+c" {
+			return Keymap{}, fmt.Errorf("key %q for %q is reserved", key, action)
+		}
+		bound := km.actions
+		if calendarActions[action] {
+			bound = km.calendar
+		}
+		if other, ok := bound[key]; ok {
+			return Keymap{}, fmt.Errorf("key %q is bound to both %q and %q", key, other, action)
+		}
+		bound[key] = action
+		km.keys[action] = key
+	}
+	return km, nil
+}
+
+// Action returns the action bound to key, or "" if none
+func (k Keymap) Action(key string) string {
+	return k.actions[key]
+}
+
+// CalendarAction returns the calendar action bound to key, or "" if none
+func (k Keymap) CalendarAction(key string) string {
+	return k.calendar[key]
+}
+
+// Key returns the key bound to action
+func (k Keymap) Key(action string) string {
+	return k.keys[action]
+}
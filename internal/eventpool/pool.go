@@ -0,0 +1,139 @@
+package eventpool
+
+import (
+	"context"
+	"mcli/internal/api"
+	"mcli/internal/profile"
+	"mcli/internal/types"
+	"mcli/internal/utils"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// UpdatedMsg is sent to every subscribed program when the pool has new events
+type UpdatedMsg struct {
+	Events    types.Events
+	FetchedAt time.Time
+}
+
+// Pool is a process-wide event list for wish mode. It refreshes on a schedule
+// and pushes every update to all live programs, so N sessions cost one fetch.
+type Pool struct {
+	client   *api.Client
+	store    *profile.Store
+	interval time.Duration
+
+	mu          sync.RWMutex
+	events      types.Events
+	fetchedAt   time.Time
+	subscribers map[*tea.Program]struct{}
+}
+
+// New creates a pool seeded with the events cached in store
+func New(client *api.Client, store *profile.Store, interval time.Duration) *Pool {
+	p := &Pool{
+		client:      client,
+		store:       store,
+		interval:    interval,
+		subscribers: map[*tea.Program]struct{}{},
+	}
+	cached, fetchedAt, err := store.LoadEvents()
+	if err != nil {
+		utils.Logger.Error("eventpool: failed to load cached events", "err", err)
+	}
+	p.events = api.SortEvents(cached, time.UTC)
+	p.fetchedAt = fetchedAt
+	return p
+}
+
+// Run refreshes the pool immediately and then every interval until ctx is done
+func (p *Pool) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.Refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh fetches events and broadcasts the result to all subscribers.
+// Concurrent calls are collapsed into one request by the API client.
+func (p *Pool) Refresh(ctx context.Context) {
+	events, err := p.client.ListEvents(ctx)
+	if err != nil {
+		utils.Logger.Error("eventpool: refresh failed", "err", err)
+		p.broadcast(api.FetchErrorMsg{Err: err})
+		return
+	}
+
+	// past events are kept, sessions pick their window in their own zone
+	sorted := api.SortEvents(events, time.UTC)
+	now := time.Now()
+	p.mu.Lock()
+	p.events = sorted
+	p.fetchedAt = now
+	p.mu.Unlock()
+
+	if err := p.store.SaveEvents(sorted, now); err != nil {
+		utils.Logger.Error("eventpool: failed to cache events", "err", err)
+	}
+	utils.Logger.Info("eventpool: refreshed", "events", len(sorted), "subscribers", p.count())
+	p.broadcast(UpdatedMsg{Events: sorted, FetchedAt: now})
+}
+
+// RefreshCmd refreshes the pool in the background on behalf of one session;
+// the result reaches that session (and all others) as a broadcast. It is not
+// tied to the session's context so a user quitting can't fail everyone's fetch.
+func (p *Pool) RefreshCmd() tea.Cmd {
+	return func() tea.Msg {
+		p.Refresh(context.Background())
+		return nil
+	}
+}
+
+// Snapshot returns the current events and when they were fetched.
+// The slice is shared and must not be modified.
+func (p *Pool) Snapshot() (types.Events, time.Time) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.events, p.fetchedAt
+}
+
+// Subscribe registers prog for updates and returns a function that removes it
+func (p *Pool) Subscribe(prog *tea.Program) func() {
+	p.mu.Lock()
+	p.subscribers[prog] = struct{}{}
+	p.mu.Unlock()
+	return func() {
+		p.mu.Lock()
+		delete(p.subscribers, prog)
+		p.mu.Unlock()
+	}
+}
+
+func (p *Pool) count() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.subscribers)
+}
+
+func (p *Pool) broadcast(msg tea.Msg) {
+	p.mu.RLock()
+	programs := make([]*tea.Program, 0, len(p.subscribers))
+	for prog := range p.subscribers {
+		programs = append(programs, prog)
+	}
+	p.mu.RUnlock()
+
+	// Send blocks until the program reads the message, don't let one slow
+	// session hold up the rest
+	for _, prog := range programs {
+		go prog.Send(msg)
+	}
+}
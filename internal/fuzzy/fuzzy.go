@@ -0,0 +1,255 @@
+// Package fuzzy scores how well a short search term matches a text: its
+// characters in order (kbn in Kubernetes), or a word within a typo or two.
+package fuzzy
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Scores of a match: every matched character earns scoreMatch, plus a bonus
+// at word starts and for runs of consecutive characters; gaps cost.
+const (
+	scoreMatch       = 16
+	bonusBoundary    = 8
+	bonusConsecutive = 6
+	penaltyGap       = 2
+	// a word within a typo scores like a subsequence with this many misses
+	typoCost = 20
+)
+
+// Pattern is a lower-cased search term, prepared once and matched against many texts
+type Pattern struct {
+	runes []rune
+	typos int // edits allowed when matching whole words, 0 for short patterns
+}
+
+// New prepares pattern for matching. Patterns of 6 or more letters also
+// match words with one typo (two from 10 letters), such as "kubernets";
+// shorter words are a typo away from too many others (rust, just, dust).
+func New(pattern string) Pattern {
+	p := Pattern{runes: []rune(strings.ToLower(pattern))}
+	switch n := len(p.runes); {
+	case strings.ContainsRune(pattern, ' '):
+	case n >= 10:
+		p.typos = 2
+	case n >= 6:
+		p.typos = 1
+	}
+	return p
+}
+
+// Strict returns p without typo tolerance, which is slow on long texts
+func (p Pattern) Strict() Pattern {
+	p.typos = 0
+	return p
+}
+
+// Score reports how well text matches p, 0 when it doesn't
+func (p Pattern) Score(text string) int {
+	score, _ := p.match(text, false)
+	return score
+}
+
+// Match is Score with the rune indexes of the matched characters of text
+func (p Pattern) Match(text string) (int, []int) {
+	return p.match(text, true)
+}
+
+func (p Pattern) match(text string, withPositions bool) (int, []int) {
+	if len(p.runes) == 0 {
+		return 0, nil
+	}
+	if score, positions := p.subsequence(text, withPositions); score > 0 {
+		return score, positions
+	}
+	if p.typos > 0 {
+		return p.typo(text, withPositions)
+	}
+	return 0, nil
+}
+
+// maxSpan is the widest stretch of text a subsequence match may cover, so
+// that short patterns don't match scattered letters of long descriptions
+func (p Pattern) maxSpan() int {
+	return len(p.runes) + 2 + len(p.runes)/2
+}
+
+// subsequence finds p's characters in order, trying every start at an
+// occurrence of p's first character and keeping the best scoring one
+func (p Pattern) subsequence(text string, withPositions bool) (int, []int) {
+	if !p.contained(text) {
+		return 0, nil
+	}
+	first := p.runes[0]
+	best, bestStart := 0, -1
+	for start := 0; start < len(text); {
+		r, size := utf8.DecodeRuneInString(text[start:])
+		if lower(r) == first {
+			if score := p.scoreAt(text, start, nil); score > best {
+				best, bestStart = score, start
+			}
+		}
+		start += size
+	}
+	if best == 0 {
+		return 0, nil
+	}
+	if !withPositions {
+		return best, nil
+	}
+	positions := make([]int, 0, len(p.runes))
+	p.scoreAt(text, bestStart, &positions)
+	return best, positions
+}
+
+// contained reports whether p's characters occur in text in order, however far apart
+func (p Pattern) contained(text string) bool {
+	pi := 0
+	for _, r := range text {
+		if lower(r) == p.runes[pi] {
+			pi++
+			if pi == len(p.runes) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scoreAt matches p greedily from byte offset start, 0 when the match is too
+// spread out. Matched rune indexes are appended to positions when it's set.
+func (p Pattern) scoreAt(text string, start int, positions *[]int) int {
+	runeIndex := 0
+	if positions != nil {
+		runeIndex = utf8.RuneCountInString(text[:start])
+	}
+	prev, _ := utf8.DecodeLastRuneInString(text[:start])
+	score, pi, span, consecutive := 0, 0, 0, 0
+	for i := start; i < len(text) && pi < len(p.runes); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+		span++
+		if span > p.maxSpan() {
+			return 0
+		}
+		if lower(r) == p.runes[pi] {
+			score += scoreMatch
+			if start == 0 && pi == 0 || !isWordRune(prev) && isWordRune(r) {
+				score += bonusBoundary
+			}
+			if consecutive > 0 {
+				score += bonusConsecutive
+			}
+			consecutive++
+			pi++
+			if positions != nil {
+				*positions = append(*positions, runeIndex)
+			}
+		} else if pi > 0 {
+			score -= penaltyGap
+			consecutive = 0
+		}
+		prev = r
+		runeIndex++
+	}
+	if pi < len(p.runes) {
+		return 0
+	}
+	return max(score, 1)
+}
+
+// typo finds the word of text closest to p within p.typos edits
+func (p Pattern) typo(text string, withPositions bool) (int, []int) {
+	bestDist, bestStart, bestLen := p.typos+1, 0, 0
+	runeIndex := 0
+	word := make([]rune, 0, 32)
+	rows := make([]int, 3*(len(p.runes)+p.typos+1))
+	flush := func() {
+		if len(word) > 0 && abs(len(word)-len(p.runes)) <= p.typos {
+			if d := distance(p.runes, word, p.typos, rows); d < bestDist {
+				bestDist, bestStart, bestLen = d, runeIndex-len(word), len(word)
+			}
+		}
+		word = word[:0]
+	}
+	for _, r := range text {
+		if isWordRune(r) {
+			word = append(word, lower(r))
+		} else {
+			flush()
+		}
+		runeIndex++
+	}
+	flush()
+
+	if bestDist > p.typos {
+		return 0, nil
+	}
+	score := max(len(p.runes)*scoreMatch-bestDist*typoCost, 1)
+	if !withPositions {
+		return score, nil
+	}
+	positions := make([]int, bestLen)
+	for i := range positions {
+		positions[i] = bestStart + i
+	}
+	return score, positions
+}
+
+// distance is the optimal string alignment distance of a and b (edits and
+// adjacent swaps), or limit+1 once it's known to exceed limit. rows is
+// scratch space for at least 3*(len(b)+1) ints.
+func distance(a, b []rune, limit int, rows []int) int {
+	n := len(b) + 1
+	prev2, prev, cur := rows[:n], rows[n:2*n], rows[2*n:3*n]
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+// lower is unicode.ToLower with a shortcut for ASCII
+func lower(r rune) rune {
+	if r < utf8.RuneSelf {
+		if 'A' <= r && r <= 'Z' {
+			r += 'a' - 'A'
+		}
+		return r
+	}
+	return unicode.ToLower(r)
+}
+
+func isWordRune(r rune) bool {
+	if r < utf8.RuneSelf {
+		return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9'
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
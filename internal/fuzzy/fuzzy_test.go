@@ -0,0 +1,90 @@
+package fuzzy
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		match         bool
+	}{
+		{"rust", "Rust Meetup", true},
+		{"meetup", "Rust Meetup", true},
+		{"kbn", "Kubernetes Kathmandu", true},
+		{"RUST", "rust", true},
+		{"काठ", "काठमाडौं", true},
+		{"", "anything", false},
+		{"go", "", false},
+
+		// typos in longer words
+		{"kubernets", "Kubernetes Kathmandu", true},
+		{"kubrenetes", "Kubernetes Kathmandu", true},
+		{"pyhton", "Python Nepal", true},
+		{"javascirpt", "JavaScript Night", true},
+		{"kubernetez", "Kubernetes", true},
+		{"kuberntez", "Kubernetes", false}, // two typos in nine letters
+
+		// short words are a typo away from unrelated words
+		{"rust", "Just Dance Night", false},
+		{"test", "Best Practices in Go", false},
+		{"java", "Lava lamp party", false},
+		{"react", "Great Dane meetup", false},
+
+		// a subsequence must not be scattered across a long text
+		{"go", "Generative art and orchestral music", false},
+		{"impact hub", "Impact Hub", true},
+		{"impact hub", "Impact Hubs", true},
+		{"impact hbu", "Impact Hub", false}, // phrases take no typos
+	}
+	for _, tt := range tests {
+		if got := New(tt.pattern).Score(tt.text) > 0; got != tt.match {
+			t.Errorf("New(%q).Score(%q) > 0 = %v, want %v", tt.pattern, tt.text, got, tt.match)
+		}
+	}
+}
+
+func TestScoreOrder(t *testing.T) {
+	p := New("go")
+	// word starts and consecutive characters score higher
+	better, worse := p.Score("Go Meetup"), p.Score("Gathering of coders")
+	if better <= worse {
+		t.Errorf("Score(Go Meetup) = %d, not above Score(Gathering of coders) = %d", better, worse)
+	}
+	exact, typo := New("kubernetes").Score("Kubernetes"), New("kubernets").Score("Kubernetes")
+	if exact <= typo {
+		t.Errorf("exact score %d, not above typo score %d", exact, typo)
+	}
+}
+
+func TestMatchPositions(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		want          []int
+	}{
+		{"go", "Go Meetup", []int{0, 1}},
+		{"meetup", "Go Meetup", []int{3, 4, 5, 6, 7, 8}},
+		{"kbn", "Kubernetes", []int{0, 2, 5}},
+		{"kubernets", "Intro to Kubernetes", []int{9, 10, 11, 12, 13, 14, 15, 16, 18}},
+		{"kubrenetes", "Intro to Kubernetes", []int{9, 10, 11, 12, 13, 14, 15, 16, 17, 18}}, // whole word for a typo
+		{"काठ", "नयाँ काठमाडौं", []int{5, 6, 7}},
+		{"rust", "Go Meetup", nil},
+	}
+	for _, tt := range tests {
+		_, got := New(tt.pattern).Match(tt.text)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("New(%q).Match(%q) = %v, want %v", tt.pattern, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestStrict(t *testing.T) {
+	p := New("kubrenetes")
+	if p.Strict().Score("Kubernetes") != 0 {
+		t.Error("Strict pattern matched a typo")
+	}
+	if p.Score("Kubernetes") == 0 {
+		t.Error("Strict changed the original pattern")
+	}
+}
@@ -0,0 +1,105 @@
+package geo
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// cities.csv lists major cities as name,country,latitude,longitude,aliases
+//
+//go:embed cities.csv
+var citiesCSV string
+
+// City is a gazetteer entry
+type City struct {
+	Name    string
+	Country string
+	Point   Point
+}
+
+var (
+	loadOnce sync.Once
+	cities   []City
+	byName   map[string]int // lower-case name or alias -> index in cities
+)
+
+func load() {
+	r := csv.NewReader(strings.NewReader(citiesCSV))
+	r.Comment = '#'
+	r.FieldsPerRecord = 5
+	records, err := r.ReadAll()
+	if err != nil {
+		// the file is compiled in, a broken one is a programming error
+		panic("geo: invalid cities.csv: " + err.Error())
+	}
+	byName = make(map[string]int, len(records)*2)
+	for _, rec := range records {
+		lat, err1 := strconv.ParseFloat(rec[2], 64)
+		lon, err2 := strconv.ParseFloat(rec[3], 64)
+		if err1 != nil || err2 != nil {
+			panic("geo: invalid coordinates for " + rec[0])
+		}
+		cities = append(cities, City{Name: rec[0], Country: rec[1], Point: Point{Lat: lat, Lon: lon}})
+		i := len(cities) - 1
+		byName[strings.ToLower(rec[0])] = i
+		for _, alias := range strings.Split(rec[4], "|") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				byName[strings.ToLower(alias)] = i
+			}
+		}
+	}
+}
+
+// Lookup finds a city by name or alias, case-insensitive. A trailing
+// ", Country" is accepted and must match the city's country.
+func Lookup(name string) (City, bool) {
+	loadOnce.Do(load)
+	name = strings.ToLower(strings.TrimSpace(name))
+	if i, ok := byName[name]; ok {
+		return cities[i], true
+	}
+	city, country, ok := strings.Cut(name, ",")
+	if !ok {
+		return City{}, false
+	}
+	i, found := byName[strings.TrimSpace(city)]
+	if !found || !strings.EqualFold(cities[i].Country, strings.TrimSpace(country)) {
+		return City{}, false
+	}
+	return cities[i], true
+}
+
+// Guess looks for a known city in a free-form address such as
+// "Jhamsikhel Rd, Lalitpur 44600, Nepal", trying the most specific part first.
+func Guess(address string) (City, bool) {
+	parts := strings.Split(address, ",")
+	for _, part := range parts {
+		// drop postal codes: "Lalitpur 44600"
+		words := strings.FieldsFunc(part, func(r rune) bool {
+			return r == ' ' || (r >= '0' && r <= '9')
+		})
+		if len(words) == 0 {
+			continue
+		}
+		if c, ok := Lookup(strings.Join(words, " ")); ok {
+			return c, true
+		}
+	}
+	return City{}, false
+}
+
+// Nearest returns the gazetteer city closest to p and its distance in km
+func Nearest(p Point) (City, float64) {
+	loadOnce.Do(load)
+	best, bestKm := City{}, math.Inf(1)
+	for _, c := range cities {
+		if km := Distance(p, c.Point); km < bestKm {
+			best, bestKm = c, km
+		}
+	}
+	return best, bestKm
+}
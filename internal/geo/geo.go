@@ -0,0 +1,103 @@
+// Package geo resolves city names to coordinates and measures distances
+// between them, without calling any online service.
+package geo
+
+import (
+	"fmt"
+	"math"
+	"mcli/internal/types"
+	"strconv"
+	"strings"
+)
+
+const earthRadiusKm = 6371.0
+
+// Point is a WGS84 coordinate in degrees
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// IsZero reports whether p is unset. Null Island is not a venue.
+func (p Point) IsZero() bool {
+	return p.Lat == 0 && p.Lon == 0
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("%.4f,%.4f", p.Lat, p.Lon)
+}
+
+// Distance returns the great-circle distance between a and b in kilometers
+func Distance(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLon := radians(b.Lon - a.Lon)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+
+// ParsePoint parses "lat,lon" in decimal degrees
+func ParsePoint(s string) (Point, error) {
+	lat, lon, ok := strings.Cut(s, ",")
+	if !ok {
+		return Point{}, fmt.Errorf("expected lat,lon: %q", s)
+	}
+	var p Point
+	var err error
+	if p.Lat, err = strconv.ParseFloat(strings.TrimSpace(lat), 64); err != nil || math.Abs(p.Lat) > 90 {
+		return Point{}, fmt.Errorf("invalid latitude %q", lat)
+	}
+	if p.Lon, err = strconv.ParseFloat(strings.TrimSpace(lon), 64); err != nil || math.Abs(p.Lon) > 180 {
+		return Point{}, fmt.Errorf("invalid longitude %q", lon)
+	}
+	return p, nil
+}
+
+// ParseRadius parses a distance like "5km", "800m", "3mi" or "10" (km) into kilometers
+func ParseRadius(s string) (float64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	units := []struct {
+		suffix string
+		km     float64
+	}{{"km", 1}, {"mi", 1.609344}, {"m", 0.001}}
+	scale := 1.0
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			s, scale = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.km
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid radius %q, use e.g. 5km, 800m or 3mi", s)
+	}
+	return v * scale, nil
+}
+
+// FormatDistance renders km for a narrow table column
+func FormatDistance(km float64) string {
+	switch {
+	case km < 1:
+		return fmt.Sprintf("%dm", int(math.Round(km*1000/100)*100))
+	case km < 10:
+		return fmt.Sprintf("%.1fkm", km)
+	default:
+		return fmt.Sprintf("%.0fkm", km)
+	}
+}
+
+// Locate returns the coordinates of a venue. Geocoded venues are exact;
+// otherwise the address is matched against the gazetteer and the city
+// center is used, with exact set to false.
+func Locate(loc types.Location) (p Point, exact bool, ok bool) {
+	if p = (Point{Lat: loc.Latitude, Lon: loc.Longitude}); !p.IsZero() {
+		return p, true, true
+	}
+	if c, found := Guess(loc.VenueAddress); found {
+		return c.Point, false, true
+	}
+	return Point{}, false, false
+}
@@ -0,0 +1,237 @@
+package ical
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"mcli/internal/types"
+	"mcli/internal/utils"
+)
+
+// Layouts events are normalized to, understood by api.ParseDateTime.
+// All-day events keep just their date; floating times keep no offset, so
+// both are read in the user's zone.
+const (
+	dateTimeLayout = "2006-01-02T15:04:05-07:00"
+	floatingLayout = "2006-01-02T15:04:05"
+	dateLayout     = "2006-01-02"
+)
+
+// property is one content line, e.g. DTSTART;TZID=Asia/Kathmandu:20251018T180000
+type property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Parse reads an iCalendar stream and returns its VEVENTs as events.
+// source is stored in Event.Source.
+func Parse(r io.Reader, source string) (types.Events, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events types.Events
+	var current []property
+	// open components, innermost last; a VALARM inside a VEVENT has its
+	// own DESCRIPTION and SUMMARY that must not replace the event's
+	var stack []string
+	for _, line := range lines {
+		prop, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+		switch prop.Name {
+		case "BEGIN":
+			name := strings.ToUpper(prop.Value)
+			if name == "VEVENT" {
+				current = nil
+			}
+			stack = append(stack, name)
+		case "END":
+			name := strings.ToUpper(prop.Value)
+			if len(stack) == 0 || stack[len(stack)-1] != name {
+				// unbalanced, drop back to the matching BEGIN if there is one
+				i := len(stack) - 1
+				for i >= 0 && stack[i] != name {
+					i--
+				}
+				if i < 0 {
+					continue
+				}
+				stack = stack[:i+1]
+			}
+			stack = stack[:len(stack)-1]
+			if name == "VEVENT" {
+				if e, ok := toEvent(current, source); ok {
+					events = append(events, e)
+				}
+			}
+		default:
+			if len(stack) > 0 && stack[len(stack)-1] == "VEVENT" {
+				current = append(current, prop)
+			}
+		}
+	}
+	return events, nil
+}
+
+// unfold joins continuation lines (starting with a space or tab) to their predecessor
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+	return lines, nil
+}
+
+// parseLine splits NAME;PARAM=VALUE:VALUE, honouring quoted parameter values
+func parseLine(line string) (property, bool) {
+	inQuotes := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return property{}, false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	prop := property{
+		Name:   strings.ToUpper(parts[0]),
+		Params: map[string]string{},
+		Value:  line[colon+1:],
+	}
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			prop.Params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return prop, true
+}
+
+func toEvent(props []property, source string) (types.Event, bool) {
+	e := types.Event{Source: source}
+	for _, p := range props {
+		switch p.Name {
+		case "UID":
+			e.ID = types.EventId(unescape(p.Value))
+		case "SUMMARY":
+			e.Title = unescape(p.Value)
+		case "DESCRIPTION":
+			e.Description = unescape(p.Value)
+		case "URL":
+			e.Url = p.Value
+		case "LOCATION":
+			e.VenueAddress = unescape(p.Value)
+			e.VenueName, _, _ = strings.Cut(e.VenueAddress, ",")
+		case "STATUS":
+			e.Status = strings.ToLower(p.Value)
+		case "DTSTART":
+			start, err := formatTime(p)
+			if err != nil {
+				return types.Event{}, false
+			}
+			e.DateTime = start
+		case "DTEND":
+			// optional, an unreadable end only loses the end
+			if end, err := formatTime(p); err == nil {
+				e.EndDateTime = end
+			}
+		}
+	}
+	if e.DateTime == "" {
+		return types.Event{}, false
+	}
+	if e.ID == "" {
+		sum := sha1.Sum([]byte(e.Title + e.DateTime))
+		e.ID = types.EventId(hex.EncodeToString(sum[:8]))
+	}
+	return e, true
+}
+
+// formatTime normalizes a DTSTART or DTEND value to one of the layouts above
+func formatTime(p property) (string, error) {
+	t, floating, err := parseTime(p)
+	switch {
+	case err != nil:
+		return "", err
+	case isDate(p):
+		return t.Format(dateLayout), nil
+	case floating:
+		return t.Format(floatingLayout), nil
+	}
+	return t.Format(dateTimeLayout), nil
+}
+
+// parseTime reads DATE-TIME (UTC, floating or TZID) and DATE values.
+// floating is true when the value has no zone, or one that can't be loaded.
+func parseTime(p property) (t time.Time, floating bool, err error) {
+	if isDate(p) {
+		t, err = time.Parse("20060102", p.Value)
+		return t, true, err
+	}
+	if strings.HasSuffix(p.Value, "Z") {
+		t, err = time.Parse("20060102T150405Z", p.Value)
+		return t, false, err
+	}
+	if tzid := p.Params["TZID"]; tzid != "" {
+		loc, err := time.LoadLocation(tzid)
+		if err == nil {
+			t, err = time.ParseInLocation("20060102T150405", p.Value, loc)
+			return t, false, err
+		}
+		// the logger isn't set up outside the app
+		if utils.Logger != nil {
+			utils.Logger.Warn("unknown TZID, reading the time as floating", "tzid", tzid, "err", err)
+		}
+	}
+	t, err = time.Parse("20060102T150405", p.Value)
+	return t, true, err
+}
+
+// isDate reports whether p holds a DATE rather than a DATE-TIME
+func isDate(p property) bool {
+	return p.Params["VALUE"] == "DATE" || len(p.Value) == len("20060102")
+}
+
+// unescape reverses the TEXT escaping of RFC 5545 section 3.3.11
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
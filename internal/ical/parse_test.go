@@ -0,0 +1,85 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"mcli/internal/api"
+	"mcli/internal/utils"
+)
+
+// calendar wraps VEVENT content lines in a VCALENDAR
+func calendar(lines ...string) string {
+	return "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:1\r\nSUMMARY:Rust Meetup\r\n" +
+		strings.Join(lines, "\r\n") + "\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+}
+
+func TestParseTimes(t *testing.T) {
+	utils.InitLogger(false)
+	if _, err := time.LoadLocation("Asia/Kathmandu"); err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	tests := []struct {
+		name       string
+		lines      []string
+		start, end string
+	}{
+		{"utc", []string{"DTSTART:20261101T121500Z"}, "2026-11-01T12:15:00+00:00", ""},
+		{"floating", []string{"DTSTART:20261101T180000"}, "2026-11-01T18:00:00", ""},
+		{"tzid", []string{"DTSTART;TZID=Asia/Kathmandu:20261101T180000"}, "2026-11-01T18:00:00+05:45", ""},
+		{"quoted tzid", []string{`DTSTART;TZID="Asia/Kathmandu":20261101T180000`}, "2026-11-01T18:00:00+05:45", ""},
+		{"unknown tzid", []string{"DTSTART;TZID=Nepal Standard Time:20261101T180000"}, "2026-11-01T18:00:00", ""},
+		{"date", []string{"DTSTART;VALUE=DATE:20261101"}, "2026-11-01", ""},
+		{"date with tzid", []string{"DTSTART;TZID=Asia/Kathmandu;VALUE=DATE:20261101"}, "2026-11-01", ""},
+		{
+			"dtend",
+			[]string{"DTSTART;TZID=Asia/Kathmandu:20261101T180000", "DTEND;TZID=Asia/Kathmandu:20261101T200000"},
+			"2026-11-01T18:00:00+05:45", "2026-11-01T20:00:00+05:45",
+		},
+		{"floating dtend", []string{"DTSTART:20261101T180000", "DTEND:20261101T200000"}, "2026-11-01T18:00:00", "2026-11-01T20:00:00"},
+		{"all day dtend", []string{"DTSTART;VALUE=DATE:20261101", "DTEND;VALUE=DATE:20261103"}, "2026-11-01", "2026-11-03"},
+		{"bad dtend", []string{"DTSTART:20261101T121500Z", "DTEND:tomorrow"}, "2026-11-01T12:15:00+00:00", ""},
+	}
+	for _, tt := range tests {
+		events, err := Parse(strings.NewReader(calendar(tt.lines...)), "ical")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(events) != 1 {
+			t.Errorf("%s: got %d events, want 1", tt.name, len(events))
+			continue
+		}
+		if e := events[0]; e.DateTime != tt.start || e.EndDateTime != tt.end {
+			t.Errorf("%s: %q to %q, want %q to %q", tt.name, e.DateTime, e.EndDateTime, tt.start, tt.end)
+		}
+	}
+}
+
+// floating times are wall clock times wherever the user is
+func TestParseFloatingInUserZone(t *testing.T) {
+	npt := time.FixedZone("NPT", 5*3600+45*60)
+	events, err := Parse(strings.NewReader(calendar("DTSTART:20261101T180000")), "ical")
+	if err != nil || len(events) != 1 {
+		t.Fatalf("Parse: %v, %d events", err, len(events))
+	}
+	got, _, err := api.ParseDateTimeIn(events[0].DateTime, npt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 11, 1, 18, 0, 0, 0, npt); !got.Equal(want) {
+		t.Errorf("read as %v, want %v", got, want)
+	}
+}
+
+func TestParseSkipsEventsWithoutStart(t *testing.T) {
+	for _, lines := range [][]string{nil, {"DTSTART:soon"}, {"DTEND:20261101T200000Z"}} {
+		events, err := Parse(strings.NewReader(calendar(lines...)), "ical")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != 0 {
+			t.Errorf("%q: got %v, want no events", lines, events)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"mcli/internal/api"
+	"mcli/internal/types"
+)
+
+// ProdID identifies mcli as the producer of exported calendars
+const ProdID = "-//mcli//mcli events//EN"
+
+// maxLineOctets is the longest content line RFC 5545 section 3.1 allows, without CRLF
+const maxLineOctets = 75
+
+// Write encodes events as an RFC 5545 VCALENDAR. Dates without a zone and
+// all-day dates are read in zone; events whose date can't be parsed are
+// skipped. stamp is used for DTSTAMP.
+func Write(w io.Writer, events types.Events, stamp time.Time, zone *time.Location) error {
+	bw := bufio.NewWriter(w)
+	cw := &contentWriter{w: bw}
+	cw.line("BEGIN:VCALENDAR")
+	cw.line("VERSION:2.0")
+	cw.line("PRODID:" + ProdID)
+	cw.line("CALSCALE:GREGORIAN")
+	cw.line("METHOD:PUBLISH")
+	for _, e := range events {
+		start, allDay, err := api.ParseDateTimeIn(e.DateTime, zone)
+		if err != nil {
+			continue
+		}
+		writeEvent(cw, e, start, allDay, stamp, zone)
+	}
+	cw.line("END:VCALENDAR")
+	if cw.err != nil {
+		return cw.err
+	}
+	return bw.Flush()
+}
+
+func writeEvent(cw *contentWriter, e types.Event, start time.Time, allDay bool, stamp time.Time, zone *time.Location) {
+	cw.line("BEGIN:VEVENT")
+	cw.line("UID:" + escape(string(e.ID)))
+	cw.line("DTSTAMP:" + stamp.UTC().Format(utcLayout))
+	if allDay {
+		// DTEND of an all-day event is the (exclusive) next day
+		day := api.InZone(start, zone)
+		cw.line("DTSTART;VALUE=DATE:" + day.Format(dateValueLayout))
+		cw.line("DTEND;VALUE=DATE:" + day.AddDate(0, 0, 1).Format(dateValueLayout))
+	} else {
+		cw.line("DTSTART:" + start.UTC().Format(utcLayout))
+		if end, ok := endOf(e, start, zone); ok {
+			cw.line("DTEND:" + end.UTC().Format(utcLayout))
+		}
+	}
+	cw.line("SUMMARY:" + escape(e.Title))
+	if loc := location(e); loc != "" {
+		cw.line("LOCATION:" + escape(loc))
+	}
+	if e.Latitude != 0 || e.Longitude != 0 {
+		cw.line(fmt.Sprintf("GEO:%.6f;%.6f", e.Latitude, e.Longitude))
+	}
+	if e.Url != "" {
+		cw.line("URL:" + e.Url)
+	}
+	if desc := description(e); desc != "" {
+		cw.line("DESCRIPTION:" + escape(desc))
+	}
+	if len(e.Tags) > 0 {
+		tags := make([]string, len(e.Tags))
+		for i, t := range e.Tags {
+			tags[i] = escape(t)
+		}
+		cw.line("CATEGORIES:" + strings.Join(tags, ","))
+	}
+	if status := statusValue(e.Status); status != "" {
+		cw.line("STATUS:" + status)
+	}
+	cw.line("END:VEVENT")
+}
+
+const (
+	utcLayout       = "20060102T150405Z"
+	dateValueLayout = "20060102"
+)
+
+// endOf returns when e ends, from EndDateTime or DurationMinutes
+func endOf(e types.Event, start time.Time, zone *time.Location) (time.Time, bool) {
+	if e.EndDateTime != "" {
+		if end, _, err := api.ParseDateTimeIn(e.EndDateTime, zone); err == nil && end.After(start) {
+			return end, true
+		}
+	}
+	if e.DurationMinutes > 0 {
+		return start.Add(time.Duration(e.DurationMinutes) * time.Minute), true
+	}
+	return time.Time{}, false
+}
+
+func location(e types.Event) string {
+	switch {
+	case e.VenueName != "" && e.VenueAddress != "" && !strings.HasPrefix(e.VenueAddress, e.VenueName):
+		return e.VenueName + ", " + e.VenueAddress
+	case e.VenueAddress != "":
+		return e.VenueAddress
+	default:
+		return e.VenueName
+	}
+}
+
+// description is the event's description followed by the links calendars don't show otherwise
+func description(e types.Event) string {
+	parts := []string{strings.TrimSpace(e.Description)}
+	if e.OnlineUrl != "" {
+		parts = append(parts, "Join online: "+e.OnlineUrl)
+	}
+	if e.Url != "" {
+		parts = append(parts, e.Url)
+	}
+	return strings.TrimSpace(strings.Join(parts, "\n\n"))
+}
+
+// statusValue maps a backend status to the VEVENT STATUS values
+func statusValue(status string) string {
+	switch strings.ToLower(status) {
+	case "cancelled", "canceled":
+		return "CANCELLED"
+	case "tentative":
+		return "TENTATIVE"
+	case "confirmed", "active", "upcoming":
+		return "CONFIRMED"
+	default:
+		return ""
+	}
+}
+
+// escape applies the TEXT escaping of RFC 5545 section 3.3.11
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ReplaceAll(s, "\r\n", "\n") {
+		switch r {
+		case '\\', ';', ',':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+		default:
+			// other control characters aren't allowed in TEXT
+			if r < 0x20 && r != '\t' {
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// contentWriter writes CRLF terminated content lines, folded at 75 octets
+// without splitting UTF-8 sequences (RFC 5545 section 3.1)
+type contentWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (cw *contentWriter) line(s string) {
+	if cw.err != nil {
+		return
+	}
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		cw.write(s[:cut] + "\r\n ")
+		s = s[cut:]
+		// continuation lines start with the folding space
+		limit = maxLineOctets - 1
+	}
+	cw.write(s + "\r\n")
+}
+
+func (cw *contentWriter) write(s string) {
+	if cw.err == nil {
+		_, cw.err = cw.w.WriteString(s)
+	}
+}
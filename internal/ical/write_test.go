@@ -0,0 +1,254 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"mcli/internal/types"
+)
+
+var stamp = time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+
+func write(t *testing.T, events types.Events) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, events, stamp, time.UTC); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	return buf.String()
+}
+
+// properties unfolds out and returns the content lines of each VEVENT
+func properties(t *testing.T, out string) [][]string {
+	t.Helper()
+	lines, err := unfold(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("unfold: %v", err)
+	}
+	var events [][]string
+	for _, line := range lines {
+		switch {
+		case line == "BEGIN:VEVENT":
+			events = append(events, nil)
+		case line == "END:VEVENT" || len(events) == 0:
+		default:
+			events[len(events)-1] = append(events[len(events)-1], line)
+		}
+	}
+	return events
+}
+
+func find(props []string, name string) (string, bool) {
+	for _, p := range props {
+		if strings.HasPrefix(p, name+":") || strings.HasPrefix(p, name+";") {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+func TestWriteCRLF(t *testing.T) {
+	out := write(t, types.Events{{ID: "1", Title: "Line\nbreak", DateTime: "2026-11-01T18:00:00Z"}})
+	if !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+		t.Errorf("output doesn't end with END:VCALENDAR CRLF: %q", out[max(0, len(out)-20):])
+	}
+	for i, line := range strings.SplitAfter(out, "\n") {
+		if line != "" && !strings.HasSuffix(line, "\r\n") {
+			t.Errorf("line %d ends without CRLF: %q", i+1, line)
+		}
+	}
+	if strings.Contains(strings.ReplaceAll(out, "\r\n", ""), "\n") {
+		t.Errorf("bare LF in output: %q", out)
+	}
+}
+
+func TestWriteFolding(t *testing.T) {
+	// multibyte runes straddle the 75 octet boundaries
+	title := strings.Repeat("काठमाडौं Go meetup ", 12)
+	out := write(t, types.Events{{ID: "1", Title: title, DateTime: "2026-11-01T18:00:00Z"}})
+
+	for i, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line %d has %d octets: %q", i+1, len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line %d splits a UTF-8 sequence: %q", i+1, line)
+		}
+	}
+	summary, _ := find(properties(t, out)[0], "SUMMARY")
+	if got := unescape(strings.TrimPrefix(summary, "SUMMARY:")); got != title {
+		t.Errorf("unfolded SUMMARY = %q, want %q", got, title)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+		lossy    bool // CR and control characters are dropped
+	}{
+		{in: "plain", want: "plain"},
+		{in: `back\slash`, want: `back\\slash`},
+		{in: "semi;colon", want: `semi\;colon`},
+		{in: "com,ma", want: `com\,ma`},
+		{in: "new\nline", want: `new\nline`},
+		{in: "tab\there", want: "tab\there"},
+		{in: `all\;,` + "\n", want: `all\\\;\,\n`},
+		{in: "crlf\r\nline", want: `crlf\nline`, lossy: true},
+		{in: "bell\a", want: "bell", lossy: true},
+	}
+	for _, tt := range tests {
+		if got := escape(tt.in); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if got := unescape(escape(tt.in)); !tt.lossy && got != tt.in {
+			t.Errorf("unescape(escape(%q)) = %q", tt.in, got)
+		}
+	}
+}
+
+func TestWriteRequiredProperties(t *testing.T) {
+	out := write(t, types.Events{
+		{ID: "1", Title: "Timed", DateTime: "2026-11-01T18:00:00Z"},
+		{ID: "2", Title: "All day", DateTime: "2026-11-02"},
+	})
+	for _, want := range []string{"BEGIN:VCALENDAR\r\n", "VERSION:2.0\r\n", "PRODID:" + ProdID + "\r\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("calendar is missing %q", want)
+		}
+	}
+	events := properties(t, out)
+	if len(events) != 2 {
+		t.Fatalf("got %d VEVENTs, want 2", len(events))
+	}
+	for i, props := range events {
+		for _, name := range []string{"UID", "DTSTAMP", "DTSTART"} {
+			if _, ok := find(props, name); !ok {
+				t.Errorf("VEVENT %d has no %s: %q", i+1, name, props)
+			}
+		}
+		if dtstamp, _ := find(props, "DTSTAMP"); dtstamp != "DTSTAMP:20261017T093000Z" {
+			t.Errorf("VEVENT %d: %s, want the stamp in UTC", i+1, dtstamp)
+		}
+	}
+}
+
+func TestWriteDates(t *testing.T) {
+	tests := []struct {
+		name      string
+		event     types.Event
+		wantStart string
+		wantEnd   string // "" when there should be no DTEND
+	}{
+		{
+			name:      "all day",
+			event:     types.Event{ID: "1", DateTime: "2026-12-31"},
+			wantStart: "DTSTART;VALUE=DATE:20261231",
+			wantEnd:   "DTEND;VALUE=DATE:20270101",
+		},
+		{
+			name:      "utc",
+			event:     types.Event{ID: "2", DateTime: "2026-11-01T18:00:00Z"},
+			wantStart: "DTSTART:20261101T180000Z",
+		},
+		{
+			name:      "offset",
+			event:     types.Event{ID: "3", DateTime: "2026-11-01T18:00:00+05:45"},
+			wantStart: "DTSTART:20261101T121500Z",
+		},
+		{
+			name:      "end",
+			event:     types.Event{ID: "4", DateTime: "2026-11-01T18:00:00Z", EventDetails: types.EventDetails{EndDateTime: "2026-11-01T20:30:00Z"}},
+			wantStart: "DTSTART:20261101T180000Z",
+			wantEnd:   "DTEND:20261101T203000Z",
+		},
+		{
+			name:      "duration",
+			event:     types.Event{ID: "5", DateTime: "2026-11-01T18:00:00Z", EventDetails: types.EventDetails{DurationMinutes: 90}},
+			wantStart: "DTSTART:20261101T180000Z",
+			wantEnd:   "DTEND:20261101T193000Z",
+		},
+		{
+			name:      "end before start",
+			event:     types.Event{ID: "6", DateTime: "2026-11-01T18:00:00Z", EventDetails: types.EventDetails{EndDateTime: "2026-11-01T17:00:00Z"}},
+			wantStart: "DTSTART:20261101T180000Z",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := properties(t, write(t, types.Events{tt.event}))
+			if len(events) != 1 {
+				t.Fatalf("got %d VEVENTs, want 1", len(events))
+			}
+			if start, _ := find(events[0], "DTSTART"); start != tt.wantStart {
+				t.Errorf("got %q, want %q", start, tt.wantStart)
+			}
+			end, ok := find(events[0], "DTEND")
+			if tt.wantEnd == "" && ok {
+				t.Errorf("got %q, want no DTEND", end)
+			}
+			if tt.wantEnd != "" && end != tt.wantEnd {
+				t.Errorf("got %q, want %q", end, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestWriteSkipsUnparseableDates(t *testing.T) {
+	out := write(t, types.Events{
+		{ID: "bad", Title: "Bad", DateTime: "next tuesday"},
+		{ID: "empty", Title: "Empty"},
+		{ID: "good", Title: "Good", DateTime: "2026-11-01T18:00:00Z"},
+	})
+	events := properties(t, out)
+	if len(events) != 1 {
+		t.Fatalf("got %d VEVENTs, want 1", len(events))
+	}
+	if uid, _ := find(events[0], "UID"); uid != "UID:good" {
+		t.Errorf("kept %q, want UID:good", uid)
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	timed := types.Event{
+		ID:          `abc,1;x`,
+		Title:       `Rust, Go; and C\C++`,
+		Description: "First line\nsecond line",
+		Url:         "https://example.com/e/1",
+		DateTime:    "2026-11-01T18:00:00Z",
+	}
+	timed.VenueName, timed.VenueAddress = "Impact Hub", "Impact Hub, Jhamsikhel, Lalitpur"
+	allDay := types.Event{ID: "2", Title: "Hackathon", DateTime: "2026-11-02"}
+
+	got, err := Parse(strings.NewReader(write(t, types.Events{timed, allDay})), "ical")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d events, want 2", len(got))
+	}
+
+	if got[0].ID != timed.ID {
+		t.Errorf("ID = %q, want %q", got[0].ID, timed.ID)
+	}
+	if got[0].Title != timed.Title {
+		t.Errorf("Title = %q, want %q", got[0].Title, timed.Title)
+	}
+	if want := description(timed); got[0].Description != want {
+		t.Errorf("Description = %q, want %q", got[0].Description, want)
+	}
+	if got[0].Url != timed.Url {
+		t.Errorf("Url = %q, want %q", got[0].Url, timed.Url)
+	}
+	if got[0].VenueAddress != timed.VenueAddress || got[0].VenueName != timed.VenueName {
+		t.Errorf("venue = %q / %q, want %q / %q", got[0].VenueName, got[0].VenueAddress, timed.VenueName, timed.VenueAddress)
+	}
+	if want := "2026-11-01T18:00:00+00:00"; got[0].DateTime != want {
+		t.Errorf("DateTime = %q, want %q", got[0].DateTime, want)
+	}
+	if got[1].ID != allDay.ID || got[1].DateTime != allDay.DateTime {
+		t.Errorf("all day = %q at %q, want %q at %q", got[1].ID, got[1].DateTime, allDay.ID, allDay.DateTime)
+	}
+}
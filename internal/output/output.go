@@ -0,0 +1,188 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mcli/internal/api"
+	"mcli/internal/types"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Output formats of the CLI subcommands
+const (
+	JSON     = "json"
+	CSV      = "csv"
+	Table    = "table"
+	Markdown = "markdown"
+)
+
+// Formats lists the accepted output formats
+var Formats = []string{Table, JSON, CSV, Markdown}
+
+// ParseFormat checks an --output value, "md" is short for markdown
+func ParseFormat(s string) (string, error) {
+	s = strings.ToLower(s)
+	if s == "md" {
+		return Markdown, nil
+	}
+	for _, f := range Formats {
+		if s == f {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q (available: %s)", s, strings.Join(Formats, ", "))
+}
+
+var header = []string{"ID", "Date", "Title", "Location", "Price", "Source", "URL"}
+
+// Events writes events in format, with dates in zone
+func Events(w io.Writer, format string, events types.Events, zone *time.Location) error {
+	switch format {
+	case JSON:
+		if events == nil {
+			events = types.Events{}
+		}
+		return WriteJSON(w, events)
+	case CSV:
+		cw := csv.NewWriter(w)
+		cw.Write(header)
+		for _, e := range events {
+			cw.Write(row(e, zone))
+		}
+		cw.Flush()
+		return cw.Error()
+	case Markdown:
+		fmt.Fprintf(w, "| %s |\n|%s\n", strings.Join(header, " | "), strings.Repeat("---|", len(header)))
+		for _, e := range events {
+			cells := row(e, zone)
+			for i, c := range cells {
+				cells[i] = strings.ReplaceAll(c, "|", `\|`)
+			}
+			if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | ")); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+		for _, e := range events {
+			fmt.Fprintln(tw, strings.Join(row(e, zone), "\t"))
+		}
+		return tw.Flush()
+	}
+}
+
+// Event writes one event in format; the table format lists every known field
+func Event(w io.Writer, format string, e types.Event, zone *time.Location) error {
+	switch format {
+	case JSON:
+		return WriteJSON(w, e)
+	case CSV, Markdown:
+		return Events(w, format, types.Events{e}, zone)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", name, value)
+		}
+	}
+	field("ID", string(e.ID))
+	field("Title", e.Title)
+	field("Date", date(e.DateTime, zone))
+	if e.EndDateTime != "" {
+		field("Ends", date(e.EndDateTime, zone))
+	}
+	field("Venue", e.VenueName)
+	field("Address", e.VenueAddress)
+	if e.Organizer != nil {
+		field("Organizer", e.Organizer.Name)
+	}
+	field("Tags", strings.Join(e.Tags, ", "))
+	if e.Price != nil {
+		field("Price", e.Price.String())
+	}
+	if e.RsvpsCount > 0 {
+		field("Going", fmt.Sprint(e.RsvpsCount))
+	}
+	field("Status", e.Status)
+	field("Source", e.Source)
+	field("URL", e.Url)
+	field("Online", e.OnlineUrl)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if desc := strings.TrimSpace(e.Description); desc != "" {
+		_, err := fmt.Fprintf(w, "\n%s\n", desc)
+		return err
+	}
+	return nil
+}
+
+var jobHeader = []string{"Location", "Status", "New events", "Message"}
+
+// Job writes the outcome of a scrape in format
+func Job(w io.Writer, format string, job api.FetchJob) error {
+	cells := []string{job.Location, job.Status, fmt.Sprint(job.NewEvents), oneLine(job.Message)}
+	switch format {
+	case JSON:
+		return WriteJSON(w, job)
+	case CSV:
+		cw := csv.NewWriter(w)
+		cw.Write(jobHeader)
+		cw.Write(cells)
+		cw.Flush()
+		return cw.Error()
+	case Markdown:
+		for i, c := range cells {
+			cells[i] = strings.ReplaceAll(c, "|", `\|`)
+		}
+		_, err := fmt.Fprintf(w, "| %s |\n|%s\n| %s |\n", strings.Join(jobHeader, " | "), strings.Repeat("---|", len(jobHeader)), strings.Join(cells, " | "))
+		return err
+	default:
+		_, err := fmt.Fprintf(w, "Fetched %d new events for %s\n", job.NewEvents, job.Location)
+		return err
+	}
+}
+
+// WriteJSON writes v as indented JSON
+func WriteJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func row(e types.Event, zone *time.Location) []string {
+	location := e.VenueName
+	if location == "" {
+		location = e.VenueAddress
+	}
+	price := ""
+	if e.Price != nil {
+		price = e.Price.String()
+	}
+	return []string{string(e.ID), date(e.DateTime, zone), oneLine(e.Title), oneLine(location), price, e.Source, e.Url}
+}
+
+// oneLine keeps a cell from breaking table and markdown rows
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// date formats s in zone, all-day dates without a time; unparseable dates are kept as they are
+func date(s string, zone *time.Location) string {
+	t, allDay, err := api.ParseDateTimeIn(s, zone)
+	switch {
+	case err != nil:
+		return s
+	case allDay:
+		return api.InZone(t, zone).Format("2006-01-02")
+	default:
+		return api.InZone(t, zone).Format("2006-01-02 15:04")
+	}
+}
@@ -0,0 +1,126 @@
+package output
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mcli/internal/api"
+	"mcli/internal/types"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// Kathmandu without tzdata
+var npt = time.FixedZone("NPT", 5*3600+45*60)
+
+func testEvents() types.Events {
+	rust := types.Event{ID: "rust", Title: "Rust Meetup", Source: "meetup", DateTime: "2026-10-14T12:15:00Z", Url: "https://meetup.com/rust"}
+	rust.VenueName, rust.VenueAddress = "Impact Hub", "Jhamsikhel, Lalitpur"
+	rust.Price = &types.Price{}
+
+	// all day, no venue name, a title that would break rows
+	design := types.Event{ID: "design", Title: "Design | Type\nNight", Source: "luma", DateTime: "2026-10-24"}
+	design.VenueAddress = "Sanepa"
+	design.Price = &types.Price{Amount: 500, Currency: "NPR"}
+
+	mystery := types.Event{ID: "mystery", Title: "Mystery", DateTime: "soon"}
+
+	return types.Events{rust, design, mystery}
+}
+
+// golden compares got with testdata/name, or rewrites it with -update
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs:\n--- got\n%s\n--- want\n%s", name, got, want)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Events(&buf, format, testEvents(), npt); err != nil {
+				t.Fatal(err)
+			}
+			golden(t, "events."+format, buf.Bytes())
+		})
+	}
+}
+
+// no events is an empty list, not null
+func TestEventsEmptyJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Events(&buf, JSON, nil, npt); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "[]\n" {
+		t.Errorf("got %q, want []", got)
+	}
+}
+
+func TestEvent(t *testing.T) {
+	e := testEvents()[0]
+	e.Description = "  Ownership and borrowing.\n"
+	e.Organizer = &types.Organizer{Name: "Rust Nepal"}
+	e.Tags = []string{"rust", "systems"}
+	e.RsvpsCount = 60
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Event(&buf, format, e, npt); err != nil {
+				t.Fatal(err)
+			}
+			golden(t, "event."+format, buf.Bytes())
+		})
+	}
+}
+
+func TestJob(t *testing.T) {
+	job := api.FetchJob{Location: "Kathmandu", Status: api.JobDone, NewEvents: 3, Message: "scraped | 2 sites"}
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Job(&buf, format, job); err != nil {
+				t.Fatal(err)
+			}
+			golden(t, "job."+format, buf.Bytes())
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in, want string
+		err      bool
+	}{
+		{"table", Table, false},
+		{"JSON", JSON, false},
+		{"csv", CSV, false},
+		{"markdown", Markdown, false},
+		{"md", Markdown, false},
+		{"yaml", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.in)
+		if got != tt.want || (err != nil) != tt.err {
+			t.Errorf("ParseFormat(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
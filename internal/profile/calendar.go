@@ -0,0 +1,67 @@
+package profile
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// CalendarToken returns the user's calendar feed token, "" when there is none
+func (s *Store) CalendarToken(userID string) (string, error) {
+	var token string
+	err := s.db.QueryRow("SELECT token FROM calendar_tokens WHERE user_id = ?", userID).Scan(&token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load calendar token: %w", err)
+	}
+	return token, nil
+}
+
+// NewCalendarToken creates a random calendar feed token for the user,
+// replacing (and so revoking) the previous one
+func (s *Store) NewCalendarToken(userID string) (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate calendar token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("DELETE FROM calendar_tokens WHERE user_id = ?", userID); err != nil {
+		return "", fmt.Errorf("failed to revoke calendar token: %w", err)
+	}
+	if _, err := tx.Exec("INSERT INTO calendar_tokens (token, user_id) VALUES (?, ?)", token, userID); err != nil {
+		return "", fmt.Errorf("failed to save calendar token: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to save calendar token: %w", err)
+	}
+	return token, nil
+}
+
+// RevokeCalendarToken deletes the user's calendar feed token
+func (s *Store) RevokeCalendarToken(userID string) error {
+	_, err := s.db.Exec("DELETE FROM calendar_tokens WHERE user_id = ?", userID)
+	return err
+}
+
+// CalendarUser returns the user a calendar feed token belongs to, "" for unknown tokens
+func (s *Store) CalendarUser(token string) (string, error) {
+	var userID string
+	err := s.db.QueryRow("SELECT user_id FROM calendar_tokens WHERE token = ?", token).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up calendar token: %w", err)
+	}
+	return userID, nil
+}
@@ -0,0 +1,96 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"mcli/internal/types"
+	"time"
+)
+
+// SaveEvents replaces the cached event list with events fetched at fetchedAt
+func (s *Store) SaveEvents(events types.Events, fetchedAt time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM events"); err != nil {
+		return fmt.Errorf("failed to clear events: %w", err)
+	}
+	for _, e := range events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode event %s: %w", e.ID, err)
+		}
+		if _, err := tx.Exec(
+			"INSERT OR REPLACE INTO events (event_id, data, fetched_at) VALUES (?, ?, ?)",
+			string(e.ID), string(data), fetchedAt,
+		); err != nil {
+			return fmt.Errorf("failed to save event: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// LoadEvents returns the cached events and when they were fetched.
+// The time is zero when the cache is empty.
+func (s *Store) LoadEvents() (types.Events, time.Time, error) {
+	rows, err := s.db.Query("SELECT data, fetched_at FROM events")
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to load events: %w", err)
+	}
+	defer rows.Close()
+
+	var events types.Events
+	var oldest time.Time
+	for rows.Next() {
+		var data string
+		var fetchedAt time.Time
+		if err := rows.Scan(&data, &fetchedAt); err != nil {
+			return nil, time.Time{}, fmt.Errorf("failed to scan event: %w", err)
+		}
+		var e types.Event
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			return nil, time.Time{}, fmt.Errorf("failed to decode cached event: %w", err)
+		}
+		events = append(events, e)
+		if oldest.IsZero() || fetchedAt.Before(oldest) {
+			oldest = fetchedAt
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to load events: %w", err)
+	}
+	return events, oldest, nil
+}
+
+// BookmarkedEvents returns the copies of userID's bookmarked events kept by
+// AddBookmark. Bookmarks made before copies were kept are missing.
+func (s *Store) BookmarkedEvents(userID string) (types.Events, error) {
+	rows, err := s.db.Query(
+		"SELECT e.data FROM bookmarks b JOIN bookmarked_events e ON e.event_id = b.event_id WHERE b.user_id = ?",
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bookmarked events: %w", err)
+	}
+	defer rows.Close()
+
+	var events types.Events
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan bookmarked event: %w", err)
+		}
+		var e types.Event
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			return nil, fmt.Errorf("failed to decode bookmarked event: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load bookmarked events: %w", err)
+	}
+	return events, nil
+}
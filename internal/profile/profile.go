@@ -1,6 +1,7 @@
 package profile
 
 import (
+	"mcli/internal/geo"
 	"mcli/internal/types"
 	"time"
 )
@@ -8,9 +9,16 @@ import (
 type UserProfile struct {
 	UserID     string
 	Location   string
+	Coords     geo.Point // resolved from Location, zero when unknown
+	Timezone   string    // IANA zone name, empty for the server's local zone
+	Window     string    // time window as read by api.ParseWindow, empty for upcoming
+	ShowPast   bool      // list past events (dimmed) instead of hiding them
+	Sort       string    // table order as read by tui.ParseSort, empty for date
+	Columns    string    // table columns as read by columns.Parse, empty for the config's
 	Bookmarks  []types.EventId
 	ReadEvents []types.EventId
-	Filters    map[string]string
+	Filters    map[string]SavedSearch // saved searches by name
+	Sources    []types.SourceSpec     // per-user providers, override the config ones by name
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 }
@@ -22,12 +30,34 @@ func New(userID string) *UserProfile {
 		UserID:     userID,
 		Bookmarks:  []types.EventId{},
 		ReadEvents: []types.EventId{},
-		Filters:    map[string]string{},
+		Filters:    map[string]SavedSearch{},
 		CreatedAt:  now,
 		UpdatedAt:  now,
 	}
 }
 
+// SavedSearch is a filter saved with the list settings it was made in
+type SavedSearch struct {
+	Query         string // filter text, see query.Parse
+	Window        string // as read by api.ParseWindow, empty for upcoming
+	ShowPast      bool
+	BookmarksOnly bool
+	UnreadOnly    bool
+}
+
+// Zone returns the user's time zone, falling back to the local zone
+// when none is set or the saved name is no longer known
+func (p *UserProfile) Zone() *time.Location {
+	if p.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
 // ToggleBookmark adds or removes an event ID from bookmarks
 func (p *UserProfile) ToggleBookmark(eventID types.EventId) bool {
 	for i, id := range p.Bookmarks {
@@ -50,6 +80,17 @@ func (p *UserProfile) IsBookmarked(eventID types.EventId) bool {
 	return false
 }
 
+// Bookmarked keeps the events in the bookmarks, in their order
+func (p *UserProfile) Bookmarked(events types.Events) types.Events {
+	var kept types.Events
+	for _, e := range events {
+		if p.IsBookmarked(e.ID) {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
 // MarkRead adds an event ID to the read list
 func (p *UserProfile) MarkRead(eventID types.EventId) {
 	for _, id := range p.ReadEvents {
@@ -69,3 +110,25 @@ func (p *UserProfile) IsRead(eventID types.EventId) bool {
 	}
 	return false
 }
+
+// SetSource adds src or replaces the source with the same name
+func (p *UserProfile) SetSource(src types.SourceSpec) {
+	for i, existing := range p.Sources {
+		if existing.Name == src.Name {
+			p.Sources[i] = src
+			return
+		}
+	}
+	p.Sources = append(p.Sources, src)
+}
+
+// RemoveSource deletes the named source, reporting whether it existed
+func (p *UserProfile) RemoveSource(name string) bool {
+	for i, src := range p.Sources {
+		if src.Name == name {
+			p.Sources = append(p.Sources[:i], p.Sources[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
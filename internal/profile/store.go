@@ -2,7 +2,9 @@ package profile
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"mcli/internal/geo"
 	"mcli/internal/types"
 	"os"
 	"path/filepath"
@@ -11,16 +13,13 @@ import (
 	_ "modernc.org/sqlite"
 )
 
-const defaultDBPath = "data/mcli.db"
-
 // Store wraps a SQLite connection for profile persistence
 type Store struct {
 	db *sql.DB
 }
 
-// OpenStore opens (or creates) the SQLite database and runs migrations
-func OpenStore() (*Store, error) {
-	dbPath := defaultDBPath
+// OpenStore opens (or creates) the SQLite database at dbPath and runs migrations
+func OpenStore(dbPath string) (*Store, error) {
 	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
 		return nil, fmt.Errorf("failed to create data dir: %w", err)
 	}
@@ -54,6 +53,11 @@ func (s *Store) migrate() error {
 	CREATE TABLE IF NOT EXISTS profiles (
 		user_id    TEXT PRIMARY KEY,
 		location   TEXT NOT NULL DEFAULT '',
+		latitude   REAL NOT NULL DEFAULT 0,
+		longitude  REAL NOT NULL DEFAULT 0,
+		timezone   TEXT NOT NULL DEFAULT '',
+		window     TEXT NOT NULL DEFAULT '',
+		show_past  BOOLEAN NOT NULL DEFAULT 0,
 		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 	);
@@ -81,24 +85,97 @@ func (s *Store) migrate() error {
 		PRIMARY KEY (user_id, name),
 		FOREIGN KEY (user_id) REFERENCES profiles(user_id)
 	);
+
+	CREATE TABLE IF NOT EXISTS sources (
+		user_id  TEXT NOT NULL,
+		name     TEXT NOT NULL,
+		kind     TEXT NOT NULL,
+		location TEXT NOT NULL DEFAULT '',
+		enabled  BOOLEAN NOT NULL DEFAULT 1,
+		PRIMARY KEY (user_id, name),
+		FOREIGN KEY (user_id) REFERENCES profiles(user_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS calendar_tokens (
+		token      TEXT PRIMARY KEY,
+		user_id    TEXT NOT NULL UNIQUE,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES profiles(user_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS bookmarked_events (
+		event_id TEXT PRIMARY KEY,
+		data     TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS events (
+		event_id   TEXT PRIMARY KEY,
+		data       TEXT NOT NULL,
+		fetched_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
 	`
 	_, err := s.db.Exec(migrations)
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
+
+	// columns added after the first release, missing from older databases
+	columns := []struct{ table, name, def string }{
+		{"profiles", "latitude", "REAL NOT NULL DEFAULT 0"},
+		{"profiles", "longitude", "REAL NOT NULL DEFAULT 0"},
+		{"profiles", "timezone", "TEXT NOT NULL DEFAULT ''"},
+		{"profiles", "window", "TEXT NOT NULL DEFAULT ''"},
+		{"profiles", "show_past", "BOOLEAN NOT NULL DEFAULT 0"},
+		{"profiles", "sort", "TEXT NOT NULL DEFAULT ''"},
+		{"profiles", "columns", "TEXT NOT NULL DEFAULT ''"},
+		{"filters", "window", "TEXT NOT NULL DEFAULT ''"},
+		{"filters", "show_past", "BOOLEAN NOT NULL DEFAULT 0"},
+		{"filters", "bookmarks_only", "BOOLEAN NOT NULL DEFAULT 0"},
+		{"filters", "unread_only", "BOOLEAN NOT NULL DEFAULT 0"},
+	}
+	for _, c := range columns {
+		if err := s.addColumn(c.table, c.name, c.def); err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
+	}
 	return nil
 }
 
+// addColumn adds a column to table unless it already exists
+func (s *Store) addColumn(table, name, def string) error {
+	rows, err := s.db.Query(fmt.Sprintf("SELECT name FROM pragma_table_info('%s')", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var existing string
+		if err := rows.Scan(&existing); err != nil {
+			return err
+		}
+		if existing == name {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	_, err = s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, name, def))
+	return err
+}
+
 // Load retrieves a user profile from the database, creating one if it doesn't exist
 func (s *Store) Load(userID string) (*UserProfile, error) {
 	p := New(userID)
 
 	// Try to load existing profile
-	var location string
+	var location, timezone, window, sort, columns string
+	var showPast bool
+	var coords geo.Point
 	var createdAt, updatedAt time.Time
 	err := s.db.QueryRow(
-		"SELECT location, created_at, updated_at FROM profiles WHERE user_id = ?", userID,
-	).Scan(&location, &createdAt, &updatedAt)
+		"SELECT location, latitude, longitude, timezone, window, show_past, sort, columns, created_at, updated_at FROM profiles WHERE user_id = ?", userID,
+	).Scan(&location, &coords.Lat, &coords.Lon, &timezone, &window, &showPast, &sort, &columns, &createdAt, &updatedAt)
 
 	if err == sql.ErrNoRows {
 		// Insert new profile
@@ -117,6 +194,12 @@ func (s *Store) Load(userID string) (*UserProfile, error) {
 	}
 
 	p.Location = location
+	p.Coords = coords
+	p.Timezone = timezone
+	p.Window = window
+	p.ShowPast = showPast
+	p.Sort = sort
+	p.Columns = columns
 	p.CreatedAt = createdAt
 	p.UpdatedAt = updatedAt
 
@@ -149,17 +232,32 @@ func (s *Store) Load(userID string) (*UserProfile, error) {
 	}
 
 	// Load filters
-	rows, err = s.db.Query("SELECT name, value FROM filters WHERE user_id = ?", userID)
+	rows, err = s.db.Query("SELECT name, value, window, show_past, bookmarks_only, unread_only FROM filters WHERE user_id = ?", userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load filters: %w", err)
 	}
 	defer rows.Close()
 	for rows.Next() {
-		var name, value string
-		if err := rows.Scan(&name, &value); err != nil {
+		var name string
+		var f SavedSearch
+		if err := rows.Scan(&name, &f.Query, &f.Window, &f.ShowPast, &f.BookmarksOnly, &f.UnreadOnly); err != nil {
 			return nil, fmt.Errorf("failed to scan filter: %w", err)
 		}
-		p.Filters[name] = value
+		p.Filters[name] = f
+	}
+
+	// Load sources
+	rows, err = s.db.Query("SELECT name, kind, location, enabled FROM sources WHERE user_id = ? ORDER BY name", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sources: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var src types.SourceSpec
+		if err := rows.Scan(&src.Name, &src.Kind, &src.Location, &src.Enabled); err != nil {
+			return nil, fmt.Errorf("failed to scan source: %w", err)
+		}
+		p.Sources = append(p.Sources, src)
 	}
 
 	return p, nil
@@ -178,11 +276,11 @@ func (s *Store) Save(p *UserProfile) error {
 
 	// Upsert profile
 	_, err = tx.Exec(`
-		INSERT INTO profiles (user_id, location, created_at, updated_at)
-		VALUES (?, ?, ?, ?)
-		ON CONFLICT(user_id) DO UPDATE SET location = ?, updated_at = ?`,
-		p.UserID, p.Location, p.CreatedAt, now,
-		p.Location, now,
+		INSERT INTO profiles (user_id, location, latitude, longitude, timezone, window, show_past, sort, columns, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET location = ?, latitude = ?, longitude = ?, timezone = ?, window = ?, show_past = ?, sort = ?, columns = ?, updated_at = ?`,
+		p.UserID, p.Location, p.Coords.Lat, p.Coords.Lon, p.Timezone, p.Window, p.ShowPast, p.Sort, p.Columns, p.CreatedAt, now,
+		p.Location, p.Coords.Lat, p.Coords.Lon, p.Timezone, p.Window, p.ShowPast, p.Sort, p.Columns, now,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to save profile: %w", err)
@@ -212,33 +310,104 @@ func (s *Store) Save(p *UserProfile) error {
 	if _, err := tx.Exec("DELETE FROM filters WHERE user_id = ?", p.UserID); err != nil {
 		return fmt.Errorf("failed to clear filters: %w", err)
 	}
-	for name, value := range p.Filters {
-		if _, err := tx.Exec("INSERT INTO filters (user_id, name, value) VALUES (?, ?, ?)", p.UserID, name, value); err != nil {
+	for name, f := range p.Filters {
+		if _, err := tx.Exec(
+			"INSERT INTO filters (user_id, name, value, window, show_past, bookmarks_only, unread_only) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			p.UserID, name, f.Query, f.Window, f.ShowPast, f.BookmarksOnly, f.UnreadOnly,
+		); err != nil {
 			return fmt.Errorf("failed to save filter: %w", err)
 		}
 	}
 
+	// Replace sources
+	if _, err := tx.Exec("DELETE FROM sources WHERE user_id = ?", p.UserID); err != nil {
+		return fmt.Errorf("failed to clear sources: %w", err)
+	}
+	for _, src := range p.Sources {
+		if _, err := tx.Exec(
+			"INSERT INTO sources (user_id, name, kind, location, enabled) VALUES (?, ?, ?, ?, ?)",
+			p.UserID, src.Name, src.Kind, src.Location, src.Enabled,
+		); err != nil {
+			return fmt.Errorf("failed to save source: %w", err)
+		}
+	}
+
 	return tx.Commit()
 }
 
-// SaveLocation updates just the location field
-func (s *Store) SaveLocation(userID, location string) error {
+// SaveLocation updates just the location and its coordinates
+func (s *Store) SaveLocation(userID, location string, coords geo.Point) error {
 	_, err := s.db.Exec(
-		"UPDATE profiles SET location = ?, updated_at = ? WHERE user_id = ?",
-		location, time.Now(), userID,
+		"UPDATE profiles SET location = ?, latitude = ?, longitude = ?, updated_at = ? WHERE user_id = ?",
+		location, coords.Lat, coords.Lon, time.Now(), userID,
 	)
 	return err
 }
 
-// AddBookmark adds a single bookmark
-func (s *Store) AddBookmark(userID string, eventID types.EventId) error {
+// SaveTimezone updates just the time zone
+func (s *Store) SaveTimezone(userID, timezone string) error {
 	_, err := s.db.Exec(
-		"INSERT OR IGNORE INTO bookmarks (user_id, event_id) VALUES (?, ?)",
-		userID, string(eventID),
+		"UPDATE profiles SET timezone = ?, updated_at = ? WHERE user_id = ?",
+		timezone, time.Now(), userID,
+	)
+	return err
+}
+
+// SaveWindow updates just the time window settings
+func (s *Store) SaveWindow(userID, window string, showPast bool) error {
+	_, err := s.db.Exec(
+		"UPDATE profiles SET window = ?, show_past = ?, updated_at = ? WHERE user_id = ?",
+		window, showPast, time.Now(), userID,
 	)
 	return err
 }
 
+// SaveSort updates just the table order
+func (s *Store) SaveSort(userID, sort string) error {
+	_, err := s.db.Exec(
+		"UPDATE profiles SET sort = ?, updated_at = ? WHERE user_id = ?",
+		sort, time.Now(), userID,
+	)
+	return err
+}
+
+// SaveColumns updates just the table columns
+func (s *Store) SaveColumns(userID, columns string) error {
+	_, err := s.db.Exec(
+		"UPDATE profiles SET columns = ?, updated_at = ? WHERE user_id = ?",
+		columns, time.Now(), userID,
+	)
+	return err
+}
+
+// AddBookmark adds a single bookmark, keeping a copy of the event for when
+// it's gone from the cache
+func (s *Store) AddBookmark(userID string, e types.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %s: %w", e.ID, err)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		"INSERT OR IGNORE INTO bookmarks (user_id, event_id) VALUES (?, ?)",
+		userID, string(e.ID),
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		"INSERT OR REPLACE INTO bookmarked_events (event_id, data) VALUES (?, ?)",
+		string(e.ID), string(data),
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // RemoveBookmark removes a single bookmark
 func (s *Store) RemoveBookmark(userID string, eventID types.EventId) error {
 	_, err := s.db.Exec(
@@ -256,3 +425,59 @@ func (s *Store) AddReadEvent(userID string, eventID types.EventId) error {
 	)
 	return err
 }
+
+// SaveFilter adds or replaces a single saved search
+func (s *Store) SaveFilter(userID, name string, f SavedSearch) error {
+	_, err := s.db.Exec(`
+		INSERT INTO filters (user_id, name, value, window, show_past, bookmarks_only, unread_only) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id, name) DO UPDATE SET value = ?, window = ?, show_past = ?, bookmarks_only = ?, unread_only = ?`,
+		userID, name, f.Query, f.Window, f.ShowPast, f.BookmarksOnly, f.UnreadOnly,
+		f.Query, f.Window, f.ShowPast, f.BookmarksOnly, f.UnreadOnly,
+	)
+	return err
+}
+
+// RenameFilter renames a saved search, replacing any search named to
+func (s *Store) RenameFilter(userID, from, to string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("DELETE FROM filters WHERE user_id = ? AND name = ?", userID, to); err != nil {
+		return fmt.Errorf("failed to clear filter: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE filters SET name = ? WHERE user_id = ? AND name = ?", to, userID, from); err != nil {
+		return fmt.Errorf("failed to rename filter: %w", err)
+	}
+	return tx.Commit()
+}
+
+// RemoveFilter removes a single saved search
+func (s *Store) RemoveFilter(userID, name string) error {
+	_, err := s.db.Exec(
+		"DELETE FROM filters WHERE user_id = ? AND name = ?",
+		userID, name,
+	)
+	return err
+}
+
+// SaveSource adds or updates a single event source
+func (s *Store) SaveSource(userID string, src types.SourceSpec) error {
+	_, err := s.db.Exec(`
+		INSERT INTO sources (user_id, name, kind, location, enabled) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(user_id, name) DO UPDATE SET kind = ?, location = ?, enabled = ?`,
+		userID, src.Name, src.Kind, src.Location, src.Enabled,
+		src.Kind, src.Location, src.Enabled,
+	)
+	return err
+}
+
+// RemoveSource removes a single event source
+func (s *Store) RemoveSource(userID, name string) error {
+	_, err := s.db.Exec(
+		"DELETE FROM sources WHERE user_id = ? AND name = ?",
+		userID, name,
+	)
+	return err
+}
@@ -0,0 +1,434 @@
+package query
+
+import (
+	"fmt"
+	"mcli/internal/api"
+	"mcli/internal/fuzzy"
+	"mcli/internal/types"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Env is what matching depends on besides the event
+type Env struct {
+	Now  time.Time
+	Zone *time.Location // nil for the local zone
+
+	scores []int // FilterScores: of each ranked term for the event being matched, -1 until known
+}
+
+// Match reports whether e matches the query
+func (q *Query) Match(e types.Event, env Env) bool {
+	if q == nil || q.root == nil {
+		return true
+	}
+	return q.root.match(e, env)
+}
+
+// Score ranks how well e matches the words and title: terms of the query,
+// 0 when there are none or they all miss
+func (q *Query) Score(e types.Event) int {
+	if q == nil {
+		return 0
+	}
+	score := 0
+	for _, t := range q.ranked {
+		score += t.score(e)
+	}
+	return score
+}
+
+// FilterScores is Filter that also returns the Score of every match by ID,
+// for Rank. Words are scored once per event, for matching and ranking both.
+// The scores are nil when the query has nothing to rank by.
+func (q *Query) FilterScores(events []types.Event, env Env) ([]types.Event, map[types.EventId]int) {
+	if q == nil || len(q.ranked) == 0 {
+		return q.Filter(events, env), nil
+	}
+	env.scores = make([]int, len(q.ranked))
+	scores := map[types.EventId]int{}
+	var matched []types.Event
+	for _, e := range events {
+		for i := range env.scores {
+			env.scores[i] = -1
+		}
+		if !q.root.match(e, env) {
+			continue
+		}
+		total := 0
+		for _, t := range q.ranked {
+			total += env.score(t, e)
+		}
+		matched = append(matched, e)
+		scores[e.ID] = total
+	}
+	return matched, scores
+}
+
+// Rank sorts events by the scores of FilterScores, best first; equal
+// scores keep their order
+func Rank(events []types.Event, scores map[types.EventId]int) []types.Event {
+	if len(scores) == 0 {
+		return events
+	}
+	ranked := slices.Clone(events)
+	slices.SortStableFunc(ranked, func(a, b types.Event) int {
+		return scores[b.ID] - scores[a.ID]
+	})
+	return ranked
+}
+
+// Highlight returns the rune indexes of title matched by the words and
+// title: terms of the query, in order
+func (q *Query) Highlight(title string) []int {
+	if q == nil {
+		return nil
+	}
+	var positions []int
+	for _, t := range q.ranked {
+		_, matched := t.pattern.Match(title)
+		positions = append(positions, matched...)
+	}
+	slices.Sort(positions)
+	return slices.Compact(positions)
+}
+
+// Filter returns the events matching the query
+func (q *Query) Filter(events []types.Event, env Env) []types.Event {
+	if q == nil || q.root == nil {
+		return events
+	}
+	var matched []types.Event
+	for _, e := range events {
+		if q.root.match(e, env) {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
+
+type node interface {
+	match(e types.Event, env Env) bool
+}
+
+type andNode []node
+
+func (n andNode) match(e types.Event, env Env) bool {
+	for _, c := range n {
+		if !c.match(e, env) {
+			return false
+		}
+	}
+	return true
+}
+
+type orNode []node
+
+func (n orNode) match(e types.Event, env Env) bool {
+	for _, c := range n {
+		if c.match(e, env) {
+			return true
+		}
+	}
+	return false
+}
+
+type notNode struct{ node }
+
+func (n notNode) match(e types.Event, env Env) bool {
+	return !n.node.match(e, env)
+}
+
+// term is a bare word or phrase (field "") or a field:value qualifier
+type term struct {
+	field   string
+	value   string        // lower-cased
+	pattern fuzzy.Pattern // bare words and title:, for scoring and highlighting
+	rank    int           // 1 + index in Query.ranked, 0 when not ranked
+	cmp     comparison
+	date    dateSpan
+}
+
+// comparison is a numeric condition such as >50, <=10, 10..20 or 5
+type comparison struct {
+	op       string // ">", ">=", "<", "<=", "=" or ".."
+	num, max float64
+}
+
+func newTerm(t token) (node, error) {
+	field := t.field
+	if alias, ok := aliases[field]; ok {
+		field = alias
+	}
+	value := strings.ToLower(t.value)
+	n := &term{field: field, value: value}
+	valuePos := t.pos + len(t.text) - len(t.value)
+	if t.quoted {
+		valuePos--
+	}
+	if value == "" {
+		if field == "" {
+			return nil, &SyntaxError{t.pos, "empty phrase"}
+		}
+		return nil, &SyntaxError{valuePos, t.field + ": needs a value"}
+	}
+
+	switch field {
+	case "", "title":
+		n.pattern = fuzzy.New(value)
+	case "venue", "desc", "organizer", "tag", "source":
+	case "is":
+		switch value {
+		case "free", "paid", "online", "offline", "past", "upcoming":
+		default:
+			return nil, &SyntaxError{valuePos, fmt.Sprintf("unknown is:%s (free, paid, online, offline, past, upcoming)", value)}
+		}
+	case "rsvp", "price":
+		if field == "price" && value == "free" {
+			value = "0"
+		}
+		cmp, err := parseComparison(value)
+		if err != nil {
+			return nil, &SyntaxError{valuePos, fmt.Sprintf("%s: %v", t.field, err)}
+		}
+		n.cmp = cmp
+	case "date":
+		span, err := parseDateSpan(value)
+		if err != nil {
+			return nil, &SyntaxError{valuePos, fmt.Sprintf("%s: %v", t.field, err)}
+		}
+		n.date = span
+	default:
+		return nil, &SyntaxError{t.pos, fmt.Sprintf("unknown field %s: (%s)", t.field, strings.Join(Fields, ", "))}
+	}
+	return n, nil
+}
+
+func (t *term) match(e types.Event, env Env) bool {
+	switch t.field {
+	case "":
+		if t.rank > 0 && env.scores != nil {
+			return env.score(t, e) > 0
+		}
+		return t.textScore(e, true) > 0
+	case "title":
+		return contains(e.Title, t.value)
+	case "venue":
+		return contains(e.VenueName, t.value) || contains(e.VenueAddress, t.value)
+	case "desc":
+		return contains(e.Description, t.value)
+	case "organizer":
+		return e.Organizer != nil && contains(e.Organizer.Name, t.value)
+	case "tag":
+		return e.HasTag(t.value)
+	case "source":
+		return strings.EqualFold(e.Source, t.value)
+	case "rsvp":
+		return t.cmp.match(float64(e.RsvpsCount))
+	case "price":
+		return e.Price != nil && t.cmp.match(e.Price.Amount)
+	case "date":
+		return t.date.match(e, env)
+	}
+
+	// is:
+	switch t.value {
+	case "free":
+		return e.IsFree()
+	case "paid":
+		return e.Price != nil && e.Price.Amount > 0
+	case "online":
+		return e.IsOnline()
+	case "offline":
+		return !e.IsOnline()
+	case "past", "upcoming":
+		start, allDay, err := api.ParseDateTimeIn(e.DateTime, env.Zone)
+		if err != nil {
+			return false
+		}
+		// all-day events last until the end of their day
+		if allDay {
+			start = start.Add(24 * time.Hour)
+		}
+		return start.Before(env.Now) == (t.value == "past")
+	}
+	return false
+}
+
+// score is how much a ranked term adds to Query.Score
+func (t *term) score(e types.Event) int {
+	if t.field == "" {
+		return t.textScore(e, false)
+	}
+	return 3 * t.pattern.Score(e.Title)
+}
+
+// score returns t.score(e), computed once per event matched by FilterScores
+func (env Env) score(t *term, e types.Event) int {
+	s := &env.scores[t.rank-1]
+	if *s < 0 {
+		*s = t.score(e)
+	}
+	return *s
+}
+
+func contains(s, lowerSubstr string) bool {
+	return strings.Contains(strings.ToLower(s), lowerSubstr)
+}
+
+// textScore is how well a bare word matches e: fuzzy in the title (which
+// counts most), venue, organizer and tags, typos not allowed in the
+// description. With first it stops at the first matching field.
+func (t *term) textScore(e types.Event, first bool) int {
+	score := 3 * t.pattern.Score(e.Title)
+	if first && score > 0 {
+		return score
+	}
+	others := []string{e.VenueName, e.VenueAddress}
+	if e.Organizer != nil {
+		others = append(others, e.Organizer.Name)
+	}
+	for _, text := range append(others, e.Tags...) {
+		score = max(score, 2*t.pattern.Score(text))
+		if first && score > 0 {
+			return score
+		}
+	}
+	return max(score, t.pattern.Strict().Score(e.Description))
+}
+
+func parseComparison(s string) (comparison, error) {
+	if lo, hi, ok := strings.Cut(s, ".."); ok {
+		min, err1 := strconv.ParseFloat(lo, 64)
+		max, err2 := strconv.ParseFloat(hi, 64)
+		if err1 != nil || err2 != nil || max < min {
+			return comparison{}, fmt.Errorf("invalid range %q, use e.g. 10..50", s)
+		}
+		return comparison{op: "..", num: min, max: max}, nil
+	}
+	c := comparison{op: "="}
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if rest, ok := strings.CutPrefix(s, op); ok {
+			c.op, s = op, rest
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return comparison{}, fmt.Errorf("invalid number %q, use e.g. >50, <=10 or 10..50", s)
+	}
+	c.num = n
+	return c, nil
+}
+
+func (c comparison) match(v float64) bool {
+	switch c.op {
+	case ">":
+		return v > c.num
+	case ">=":
+		return v >= c.num
+	case "<":
+		return v < c.num
+	case "<=":
+		return v <= c.num
+	case "..":
+		return v >= c.num && v <= c.max
+	default:
+		return v == c.num
+	}
+}
+
+// dateSpan is a date: value, resolved against Env.Now when matching
+type dateSpan struct {
+	op  string // "" for a span of days, or ">", ">=", "<", "<=" before a date
+	day string // 2006-01-02, with op
+	// the span of days, without op
+	window api.Window
+	rel    string // this-week, next-week, tomorrow or next-month, which api.Window lacks
+}
+
+// relative spans on top of the api.Window presets
+var relativeSpans = []string{"tomorrow", "this-week", "next-week", "next-month"}
+
+func parseDateSpan(s string) (dateSpan, error) {
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if day, ok := strings.CutPrefix(s, op); ok {
+			if _, err := time.Parse("2006-01-02", day); err != nil {
+				return dateSpan{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD", day)
+			}
+			return dateSpan{op: op, day: day}, nil
+		}
+	}
+	switch s {
+	case "this-month":
+		s = api.WindowMonth
+	case "past":
+		return dateSpan{}, fmt.Errorf("use is:past")
+	}
+	for _, rel := range relativeSpans {
+		if s == rel {
+			return dateSpan{rel: rel}, nil
+		}
+	}
+	w, err := api.ParseWindow(s)
+	if err != nil && s != "" && s[0] >= '0' && s[0] <= '9' {
+		return dateSpan{}, err
+	}
+	if err != nil {
+		return dateSpan{}, fmt.Errorf("unknown date %q, use today, tomorrow, weekend, this-week, next-week, month, next-month, YYYY-MM-DD, a..b or >YYYY-MM-DD", s)
+	}
+	return dateSpan{window: w}, nil
+}
+
+// bounds returns the span as [from, to); to is zero when open ended
+func (d dateSpan) bounds(env Env) (from, to time.Time) {
+	today := api.StartOfDay(env.Now, env.Zone)
+	if d.op != "" {
+		day, _ := time.ParseInLocation("2006-01-02", d.day, today.Location())
+		switch d.op {
+		case ">":
+			return day.AddDate(0, 0, 1), time.Time{}
+		case ">=":
+			return day, time.Time{}
+		case "<":
+			return time.Time{}, day
+		default:
+			return time.Time{}, day.AddDate(0, 0, 1)
+		}
+	}
+
+	// weeks start on Monday
+	monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	switch d.rel {
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), today.AddDate(0, 0, 2)
+	case "this-week":
+		return today, monday.AddDate(0, 0, 7)
+	case "next-week":
+		return monday.AddDate(0, 0, 7), monday.AddDate(0, 0, 14)
+	case "next-month":
+		y, m, _ := today.Date()
+		first := time.Date(y, m+1, 1, 0, 0, 0, 0, today.Location())
+		return first, first.AddDate(0, 1, 0)
+	}
+	return d.window.Bounds(env.Now, env.Zone)
+}
+
+func (d dateSpan) match(e types.Event, env Env) bool {
+	start, allDay, err := api.ParseDateTimeIn(e.DateTime, env.Zone)
+	if err != nil {
+		return false
+	}
+	from, to := d.bounds(env)
+	end := start
+	// all-day events last until the end of their day
+	if allDay {
+		end = start.Add(24*time.Hour - time.Nanosecond)
+	}
+	if !from.IsZero() && end.Before(from) {
+		return false
+	}
+	return to.IsZero() || start.Before(to)
+}
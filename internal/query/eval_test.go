@@ -0,0 +1,268 @@
+package query
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"mcli/internal/types"
+)
+
+// Kathmandu without tzdata; Wednesday 2026-10-14 at noon there
+var (
+	npt = time.FixedZone("NPT", 5*3600+45*60)
+	env = Env{Now: time.Date(2026, 10, 14, 12, 0, 0, 0, npt), Zone: npt}
+)
+
+func testEvents() []types.Event {
+	rust := types.Event{ID: "rust", Title: "Rust Meetup", Description: "Ownership and borrowing", Source: "meetup", DateTime: "2026-10-14T18:00:00+05:45"}
+	rust.VenueName, rust.VenueAddress = "Impact Hub", "Jhamsikhel, Lalitpur"
+	rust.Organizer = &types.Organizer{Name: "Rust Nepal"}
+	rust.Tags = []string{"rust", "systems"}
+	rust.RsvpsCount = 60
+	rust.Price = &types.Price{Amount: 0}
+
+	golang := types.Event{ID: "go", Title: "Go Workshop", Description: "Hands on concurrency", Source: "luma", DateTime: "2026-10-15T10:00:00+05:45"}
+	golang.VenueName = "Online"
+	golang.OnlineUrl = "https://meet.example.com/go"
+	golang.Tags = []string{"golang"}
+	golang.RsvpsCount = 20
+	golang.Price = &types.Price{Amount: 500, Currency: "NPR"}
+
+	// all day, price unknown
+	design := types.Event{ID: "design", Title: "Design Night", Description: "Posters and type", Source: "meetup", DateTime: "2026-10-24"}
+	design.VenueName, design.VenueAddress = "Café Soma", "Sanepa"
+
+	past := types.Event{ID: "past", Title: "Old Hackathon", Source: "luma", DateTime: "2026-10-10T09:00:00+05:45"}
+	past.RsvpsCount = 100
+	past.Price = &types.Price{Amount: 10}
+
+	summit := types.Event{ID: "summit", Title: "Cloud Summit", DateTime: "2026-11-05T09:00:00+05:45"}
+	summit.Type = "online"
+	summit.RsvpsCount = 200
+	summit.Price = &types.Price{Amount: 50, Currency: "USD"}
+
+	hike := types.Event{ID: "hike", Title: "Saturday Hike", Source: "meetup", DateTime: "2026-10-17T07:00:00+05:45"}
+
+	mystery := types.Event{ID: "mystery", Title: "Mystery", DateTime: "soon"}
+
+	return []types.Event{rust, golang, design, past, summit, hike, mystery}
+}
+
+func ids(events []types.Event) []types.EventId {
+	var ids []types.EventId
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestFilter(t *testing.T) {
+	all := []types.EventId{"rust", "go", "design", "past", "summit", "hike", "mystery"}
+	tests := []struct {
+		query string
+		want  []types.EventId
+	}{
+		{"", all},
+
+		// bare words search title, venue, organizer, tags and description
+		{"rust", []types.EventId{"rust"}},
+		{"MEETUP", []types.EventId{"rust"}},
+		{"hub", []types.EventId{"rust"}},
+		{"nepal", []types.EventId{"rust"}},
+		{"systems", []types.EventId{"rust"}},
+		{"concurrency", []types.EventId{"go"}},
+		{"sanepa", []types.EventId{"design"}},
+		{`"impact hub"`, []types.EventId{"rust"}},
+		{`"hub impact"`, nil},
+
+		// boolean structure
+		{"rust OR design", []types.EventId{"rust", "design"}},
+		{"rust design", nil},
+		{"-rust", []types.EventId{"go", "design", "past", "summit", "hike", "mystery"}},
+		{"!rust !design", []types.EventId{"go", "past", "summit", "hike", "mystery"}},
+		{"NOT (rust OR design)", []types.EventId{"go", "past", "summit", "hike", "mystery"}},
+		{"source:meetup -is:free rsvp:<10", []types.EventId{"design", "hike"}},
+		{"(source:luma OR #rust) date:this-week", []types.EventId{"rust", "go"}},
+		{"NOT source:meetup is:paid", []types.EventId{"go", "past", "summit"}},
+
+		// fields and aliases
+		{"title:go", []types.EventId{"go"}},
+		{"title:hub", nil},
+		{"venue:soma", []types.EventId{"design"}},
+		{"location:sanepa", []types.EventId{"design"}},
+		{"where:online", []types.EventId{"go"}},
+		{"desc:posters", []types.EventId{"design"}},
+		{"description:borrowing", []types.EventId{"rust"}},
+		{"organizer:nepal", []types.EventId{"rust"}},
+		{"org:nepal", []types.EventId{"rust"}},
+		{"host:nepal", []types.EventId{"rust"}},
+		{"tag:golang", []types.EventId{"go"}},
+		{"tag:go", nil},
+		{"#RUST", []types.EventId{"rust"}},
+		{"source:luma", []types.EventId{"go", "past"}},
+		{"source:LUMA", []types.EventId{"go", "past"}},
+
+		// is:
+		{"is:free", []types.EventId{"rust"}},
+		{"is:paid", []types.EventId{"go", "past", "summit"}},
+		{"is:online", []types.EventId{"go", "summit"}},
+		{"is:offline", []types.EventId{"rust", "design", "past", "hike", "mystery"}},
+		{"is:past", []types.EventId{"past"}},
+		{"is:upcoming", []types.EventId{"rust", "go", "design", "summit", "hike"}},
+
+		// rsvp: and price: comparisons and ranges
+		{"rsvp:60", []types.EventId{"rust"}},
+		{"rsvp:=60", []types.EventId{"rust"}},
+		{"rsvp:>50", []types.EventId{"rust", "past", "summit"}},
+		{"rsvp:>=100", []types.EventId{"past", "summit"}},
+		{"rsvp:<20", []types.EventId{"design", "hike", "mystery"}},
+		{"rsvp:<=20", []types.EventId{"go", "design", "hike", "mystery"}},
+		{"rsvp:20..100", []types.EventId{"rust", "go", "past"}},
+		{"going:>150", []types.EventId{"summit"}},
+		{"price:free", []types.EventId{"rust"}},
+		{"price:0", []types.EventId{"rust"}},
+		{"price:>=50", []types.EventId{"go", "summit"}},
+		{"price:<20", []types.EventId{"rust", "past"}},
+		{"price:10..50", []types.EventId{"past", "summit"}},
+		{"price:12.5..49.99", nil},
+		{"-price:>0", []types.EventId{"rust", "design", "hike", "mystery"}},
+
+		// date: presets, relative spans, days, ranges and bounds
+		{"date:today", []types.EventId{"rust"}},
+		{"date:tomorrow", []types.EventId{"go"}},
+		{"when:tomorrow", []types.EventId{"go"}},
+		{"date:weekend", []types.EventId{"hike"}},
+		{"date:week", []types.EventId{"rust", "go", "hike"}},
+		{"date:7d", []types.EventId{"rust", "go", "hike"}},
+		{"date:this-week", []types.EventId{"rust", "go", "hike"}},
+		{"date:next-week", []types.EventId{"design"}},
+		{"date:month", []types.EventId{"rust", "go", "design", "hike"}},
+		{"date:this-month", []types.EventId{"rust", "go", "design", "hike"}},
+		{"date:next-month", []types.EventId{"summit"}},
+		{"date:upcoming", []types.EventId{"rust", "go", "design", "summit", "hike"}},
+		{"date:2026-10-24", []types.EventId{"design"}},
+		{"date:2026-10-15..2026-10-17", []types.EventId{"go", "hike"}},
+		{"date:>2026-10-15", []types.EventId{"design", "summit", "hike"}},
+		{"date:>=2026-10-15", []types.EventId{"go", "design", "summit", "hike"}},
+		{"date:<2026-10-14", []types.EventId{"past"}},
+		{"date:<=2026-10-14", []types.EventId{"rust", "past"}},
+	}
+	events := testEvents()
+	for _, tt := range tests {
+		q, err := Parse(tt.query)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.query, err)
+			continue
+		}
+		if got := ids(q.Filter(events, env)); !slices.Equal(got, tt.want) {
+			t.Errorf("Filter(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+		for _, e := range events {
+			if q.Match(e, env) != slices.Contains(tt.want, e.ID) {
+				t.Errorf("Match(%q, %s) = %v, disagrees with Filter", tt.query, e.ID, q.Match(e, env))
+			}
+		}
+	}
+}
+
+// days are cut in Env.Zone
+func TestFilterZone(t *testing.T) {
+	late := types.Event{ID: "late", DateTime: "2026-10-14T23:30:00Z"}
+	q, err := Parse("date:today")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Match(late, env) {
+		t.Errorf("23:30 UTC matched date:today in Kathmandu, where it's already tomorrow")
+	}
+	if !q.Match(late, Env{Now: env.Now, Zone: time.UTC}) {
+		t.Errorf("23:30 UTC didn't match date:today in UTC")
+	}
+}
+
+// all-day events last until the end of their day
+func TestAllDayToday(t *testing.T) {
+	today := types.Event{ID: "today", DateTime: "2026-10-14"}
+	evening := Env{Now: time.Date(2026, 10, 14, 21, 0, 0, 0, npt), Zone: npt}
+	for query, want := range map[string]bool{"is:upcoming": true, "is:past": false, "date:today": true, "date:<2026-10-14": false} {
+		q, err := Parse(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := q.Match(today, evening); got != want {
+			t.Errorf("Match(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestNilQuery(t *testing.T) {
+	var q *Query
+	events := testEvents()
+	if got := q.Filter(events, env); len(got) != len(events) {
+		t.Errorf("nil Filter kept %d of %d events", len(got), len(events))
+	}
+	if !q.Match(events[0], env) {
+		t.Errorf("nil Match = false")
+	}
+}
+
+// FilterScores keeps what Filter keeps, scored as Score would
+func TestFilterScores(t *testing.T) {
+	events := testEvents()
+	for _, query := range []string{"", "rust", "meetup OR design", "-rust", "nepl", "source:luma", "#rust date:today", `"impact hub" OR night`} {
+		q, err := Parse(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, scores := q.FilterScores(events, env)
+		if want := q.Filter(events, env); !slices.Equal(ids(got), ids(want)) {
+			t.Errorf("FilterScores(%q) = %v, Filter = %v", query, ids(got), ids(want))
+		}
+		for _, e := range got {
+			if scores != nil && scores[e.ID] != q.Score(e) {
+				t.Errorf("FilterScores(%q) scored %s %d, Score = %d", query, e.ID, scores[e.ID], q.Score(e))
+			}
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	events := testEvents()[:4]
+	scores := map[types.EventId]int{"rust": 10, "go": 30, "design": 10, "past": 20}
+	// best first, equal scores keep their order
+	if got, want := ids(Rank(events, scores)), []types.EventId{"go", "past", "rust", "design"}; !slices.Equal(got, want) {
+		t.Errorf("Rank = %v, want %v", got, want)
+	}
+	if got := ids(Rank(events, nil)); !slices.Equal(got, ids(events)) {
+		t.Errorf("Rank without scores = %v, want %v", got, ids(events))
+	}
+}
+
+func BenchmarkFilterScores(b *testing.B) {
+	words := strings.Fields("rust go kubernetes design hike meetup workshop cloud summit python night talk community open source data kathmandu lalitpur")
+	events := make([]types.Event, 5000)
+	for i := range events {
+		var desc strings.Builder
+		for j := 0; desc.Len() < 2000; j++ {
+			desc.WriteString(words[(i*7+j*3)%len(words)])
+			desc.WriteByte(' ')
+		}
+		e := types.Event{ID: types.EventId(strconv.Itoa(i)), Title: words[i%len(words)] + " " + words[(i/3)%len(words)], Description: desc.String(), DateTime: "2026-10-20T18:00:00+05:45"}
+		e.VenueName = words[(i/5)%len(words)]
+		events[i] = e
+	}
+	for _, query := range []string{"kubernets", "rust OR python -cloud", "communty date:week"} {
+		q, err := Parse(query)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(query, func(b *testing.B) {
+			for b.Loop() {
+				Rank(q.FilterScores(events, env))
+			}
+		})
+	}
+}
@@ -0,0 +1,352 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Query is a parsed filter such as
+//
+//	rust venue:"impact hub" (source:luma OR source:meetup) -is:online rsvp:>50
+//
+// Words are ANDed unless joined with OR (or |); NOT, - and ! negate the
+// term or group that follows. Bare words and "quoted phrases" match the
+// title, venue, description, organizer and tags fuzzily (see package
+// fuzzy); field:value narrows a single field (see Fields). #tag is short
+// for tag:tag.
+type Query struct {
+	source string
+	root   node    // nil matches everything
+	ranked []*term // words and title: terms outside NOT, which Score ranks by
+}
+
+// Fields lists the qualifiers Parse accepts, aliases included
+var Fields = []string{"title", "venue", "desc", "organizer", "tag", "source", "is", "rsvp", "price", "date"}
+
+// field aliases
+var aliases = map[string]string{
+	"location":    "venue",
+	"where":       "venue",
+	"description": "desc",
+	"org":         "organizer",
+	"host":        "organizer",
+	"going":       "rsvp",
+	"when":        "date",
+}
+
+// SyntaxError is a parse error at a byte offset of the query
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("col %d: %s", e.Pos+1, e.Msg)
+}
+
+// Parse reads a query; the empty query matches every event
+func Parse(s string) (*Query, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, end: len(s)}
+	q := &Query{source: s}
+	if len(tokens) == 0 {
+		return q, nil
+	}
+	if q.root, err = p.or(); err != nil {
+		return nil, err
+	}
+	q.ranked = rankedTerms(q.root)
+	for i, t := range q.ranked {
+		t.rank = i + 1
+	}
+	if t, ok := p.peek(); ok {
+		if t.kind == tokRParen {
+			return nil, &SyntaxError{t.pos, "unmatched )"}
+		}
+		return nil, &SyntaxError{t.pos, fmt.Sprintf("unexpected %q", t.text)}
+	}
+	return q, nil
+}
+
+// String returns the query as it was written
+func (q *Query) String() string {
+	if q == nil {
+		return ""
+	}
+	return q.source
+}
+
+// Text returns the words and phrases every match must contain, for
+// backends that only do text search. It is empty when there are none or
+// the query uses OR at the top.
+func (q *Query) Text() string {
+	if q == nil {
+		return ""
+	}
+	var words []string
+	var collect func(n node)
+	collect = func(n node) {
+		switch n := n.(type) {
+		case andNode:
+			for _, c := range n {
+				collect(c)
+			}
+		case *term:
+			if n.field == "" {
+				words = append(words, n.value)
+			}
+		}
+	}
+	collect(q.root)
+	return strings.Join(words, " ")
+}
+
+func rankedTerms(n node) []*term {
+	var terms []*term
+	switch n := n.(type) {
+	case andNode:
+		for _, c := range n {
+			terms = append(terms, rankedTerms(c)...)
+		}
+	case orNode:
+		for _, c := range n {
+			terms = append(terms, rankedTerms(c)...)
+		}
+	case *term:
+		if n.field == "" || n.field == "title" {
+			terms = append(terms, n)
+		}
+	}
+	return terms
+}
+
+type tokenKind int
+
+const (
+	tokTerm tokenKind = iota
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind   tokenKind
+	pos    int
+	text   string // as written
+	field  string // tokTerm: qualifier, "" for bare words
+	value  string // tokTerm: unquoted value
+	quoted bool
+}
+
+func lex(s string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokLParen, pos: i, text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokRParen, pos: i, text: ")"})
+			i++
+		case c == '|':
+			text := "|"
+			if strings.HasPrefix(s[i:], "||") {
+				text = "||"
+			}
+			tokens = append(tokens, token{kind: tokOr, pos: i, text: text})
+			i += len(text)
+		case c == '-' || c == '!':
+			// a lone - is more likely a half typed -word than text to find
+			if i+1 == len(s) || isDelim(s[i+1]) && s[i+1] != '(' && s[i+1] != '"' {
+				return nil, &SyntaxError{i, string(c) + " needs a term after it"}
+			}
+			tokens = append(tokens, token{kind: tokNot, pos: i, text: string(c)})
+			i++
+		default:
+			t, next, err := lexTerm(s, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, t)
+			i = next
+		}
+	}
+	return tokens, nil
+}
+
+// lexTerm reads a word, "phrase", field:value or field:"phrase" at i
+func lexTerm(s string, i int) (token, int, error) {
+	start := i
+	t := token{kind: tokTerm, pos: start}
+
+	// a qualifier is letters followed by a colon, other colons are text (18:00, https://)
+	j := i
+	for j < len(s) && s[j] < unicode.MaxASCII && unicode.IsLetter(rune(s[j])) {
+		j++
+	}
+	if j > i && j < len(s) && s[j] == ':' && !strings.HasPrefix(s[j:], "://") {
+		t.field = strings.ToLower(s[i:j])
+		i = j + 1
+	} else if s[i] == '#' {
+		if i+1 == len(s) || isDelim(s[i+1]) && s[i+1] != '"' {
+			return t, 0, &SyntaxError{i, "# needs a tag after it"}
+		}
+		t.field = "tag"
+		i++
+	}
+
+	if i < len(s) && s[i] == '"' {
+		end := strings.IndexByte(s[i+1:], '"')
+		if end < 0 {
+			return t, 0, &SyntaxError{i, "unclosed quote"}
+		}
+		t.value = s[i+1 : i+1+end]
+		t.quoted = true
+		i += end + 2
+	} else {
+		j := i
+		for j < len(s) && !isDelim(s[j]) {
+			j++
+		}
+		t.value = s[i:j]
+		i = j
+	}
+	t.text = s[start:i]
+
+	if t.field == "" && !t.quoted {
+		switch t.value {
+		case "AND", "&", "&&":
+			t.kind = tokAnd
+		case "OR", "||":
+			t.kind = tokOr
+		case "NOT":
+			t.kind = tokNot
+		}
+	}
+	return t, i, nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+func isDelim(c byte) bool {
+	return isSpace(c) || c == '(' || c == ')' || c == '"'
+}
+
+type parser struct {
+	tokens []token
+	i      int
+	end    int // length of the query, where "missing" errors point
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.i >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.i], true
+}
+
+// or = and { OR and }
+func (p *parser) or() (node, error) {
+	first, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	nodes := orNode{first}
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind != tokOr {
+			break
+		}
+		p.i++
+		n, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	if len(nodes) == 1 {
+		return first, nil
+	}
+	return nodes, nil
+}
+
+// and = unary { [AND] unary }
+func (p *parser) and() (node, error) {
+	var nodes andNode
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind == tokOr || t.kind == tokRParen {
+			break
+		}
+		if t.kind == tokAnd {
+			if len(nodes) == 0 {
+				return nil, &SyntaxError{t.pos, t.text + " needs a term before it"}
+			}
+			p.i++
+			if next, ok := p.peek(); !ok || next.kind == tokOr || next.kind == tokRParen {
+				return nil, &SyntaxError{t.pos, t.text + " needs a term after it"}
+			}
+			continue
+		}
+		n, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	if len(nodes) == 0 {
+		pos := p.end
+		if t, ok := p.peek(); ok {
+			pos = t.pos
+		}
+		if p.i > 0 && p.tokens[p.i-1].kind == tokOr {
+			return nil, &SyntaxError{p.tokens[p.i-1].pos, p.tokens[p.i-1].text + " needs a term on both sides"}
+		}
+		return nil, &SyntaxError{pos, "expected a term"}
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+// unary = NOT unary | ( or ) | term
+func (p *parser) unary() (node, error) {
+	t, _ := p.peek()
+	p.i++
+	switch t.kind {
+	case tokNot:
+		if _, ok := p.peek(); !ok {
+			return nil, &SyntaxError{t.pos, t.text + " needs a term after it"}
+		}
+		n, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{n}, nil
+	case tokLParen:
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if closing, ok := p.peek(); !ok || closing.kind != tokRParen {
+			return nil, &SyntaxError{t.pos, "unclosed ("}
+		}
+		p.i++
+		return n, nil
+	default:
+		return newTerm(t)
+	}
+}
@@ -0,0 +1,225 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// dump writes n as an s-expression, e.g. (or (and a b) (not venue:c))
+func dump(n node) string {
+	list := func(op string, nodes []node) string {
+		parts := []string{op}
+		for _, c := range nodes {
+			parts = append(parts, dump(c))
+		}
+		return "(" + strings.Join(parts, " ") + ")"
+	}
+	switch n := n.(type) {
+	case nil:
+		return "<all>"
+	case andNode:
+		return list("and", n)
+	case orNode:
+		return list("or", n)
+	case notNode:
+		return "(not " + dump(n.node) + ")"
+	case *term:
+		value := n.value
+		if strings.ContainsAny(value, " ()") {
+			value = fmt.Sprintf("%q", value)
+		}
+		if n.field == "" {
+			return value
+		}
+		return n.field + ":" + value
+	}
+	return fmt.Sprintf("<%T>", n)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "<all>"},
+		{"   ", "<all>"},
+		{"rust", "rust"},
+		{"Rust", "rust"},
+
+		// AND binds tighter than OR, NOT tightest
+		{"a b", "(and a b)"},
+		{"a AND b", "(and a b)"},
+		{"a && b", "(and a b)"},
+		{"a & b", "(and a b)"},
+		{"a OR b", "(or a b)"},
+		{"a | b", "(or a b)"},
+		{"a || b", "(or a b)"},
+		{"a b OR c", "(or (and a b) c)"},
+		{"a OR b c", "(or a (and b c))"},
+		{"a OR b OR c", "(or a b c)"},
+		{"NOT a b", "(and (not a) b)"},
+		{"NOT a OR b", "(or (not a) b)"},
+		{"NOT NOT a", "(not (not a))"},
+		{"a or b", "(and a or b)"}, // operators are upper case
+
+		// grouping
+		{"(a OR b) c", "(and (or a b) c)"},
+		{"a (b OR c)", "(and a (or b c))"},
+		{"((a))", "a"},
+		{"NOT (a OR b)", "(not (or a b))"},
+		{"(a OR b) (c OR d)", "(and (or a b) (or c d))"},
+
+		// - and ! negate the term or group right after them
+		{"-a", "(not a)"},
+		{"!a", "(not a)"},
+		{"a -b", "(and a (not b))"},
+		{"-(a OR b)", "(not (or a b))"},
+		{`-"impact hub"`, `(not "impact hub")`},
+		{"--a", "(not (not a))"},
+		{"-source:luma", "(not source:luma)"},
+		{"co-op", "co-op"},
+		{"a-", "a-"},
+
+		// phrases
+		{`"impact hub"`, `"impact hub"`},
+		{`"Impact Hub" rust`, `(and "impact hub" rust)`},
+		{`"OR"`, "or"},
+		{`"a" OR "b"`, "(or a b)"},
+
+		// fields and aliases
+		{"title:go", "title:go"},
+		{"TITLE:Go", "title:go"},
+		{`venue:"impact hub"`, `venue:"impact hub"`},
+		{"location:hub", "venue:hub"},
+		{"where:hub", "venue:hub"},
+		{"desc:ownership", "desc:ownership"},
+		{"description:ownership", "desc:ownership"},
+		{"organizer:nepal", "organizer:nepal"},
+		{"org:nepal", "organizer:nepal"},
+		{"host:nepal", "organizer:nepal"},
+		{"tag:rust", "tag:rust"},
+		{"#rust", "tag:rust"},
+		{`#"machine learning"`, `tag:"machine learning"`},
+		{"source:luma", "source:luma"},
+		{"is:free", "is:free"},
+		{"rsvp:>50", "rsvp:>50"},
+		{"going:>50", "rsvp:>50"},
+		{"price:10..50", "price:10..50"},
+		{"date:today", "date:today"},
+		{"when:today", "date:today"},
+
+		// colons and # inside words are text
+		{"18:00", "18:00"},
+		{"https://example.com", "https://example.com"},
+		{":", ":"},
+		{"c#", "c#"},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if got := dump(q.root); got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+		if q.String() != tt.in {
+			t.Errorf("Parse(%q).String() = %q", tt.in, q.String())
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		in      string
+		wantPos int
+		wantMsg string
+	}{
+		{`"unclosed`, 0, "unclosed quote"},
+		{`venue:"impact`, 6, "unclosed quote"},
+		{`""`, 0, "empty phrase"},
+		{"a (b", 2, "unclosed ("},
+		{"(a OR b", 0, "unclosed ("},
+		{"a)", 1, "unmatched )"},
+		{"()", 1, "expected a term"},
+		{"OR a", 0, "expected a term"},
+		{"a OR", 2, "OR needs a term on both sides"},
+		{"a | | b", 2, "| needs a term on both sides"},
+		{"AND a", 0, "AND needs a term before it"},
+		{"a AND", 2, "AND needs a term after it"},
+		{"a AND OR b", 2, "AND needs a term after it"},
+		{"NOT", 0, "NOT needs a term after it"},
+		{"a NOT", 2, "NOT needs a term after it"},
+
+		// half typed negations and tags are errors rather than text
+		{"-", 0, "- needs a term after it"},
+		{"!", 0, "! needs a term after it"},
+		{"a - b", 2, "- needs a term after it"},
+		{"(a -)", 3, "- needs a term after it"},
+		{"#", 0, "# needs a tag after it"},
+		{"rust #", 5, "# needs a tag after it"},
+
+		{"title:", 6, "title: needs a value"},
+		{`venue:""`, 7, "venue: needs a value"},
+		{"nope:x", 0, "unknown field nope: (title, venue, desc, organizer, tag, source, is, rsvp, price, date)"},
+		{"is:maybe", 3, "unknown is:maybe (free, paid, online, offline, past, upcoming)"},
+		{"rsvp:>x", 5, `rsvp: invalid number "x", use e.g. >50, <=10 or 10..50`},
+		{"going:lots", 6, `going: invalid number "lots", use e.g. >50, <=10 or 10..50`},
+		{"price:50..10", 6, `price: invalid range "50..10", use e.g. 10..50`},
+		{"date:someday", 5, `date: unknown date "someday", use today, tomorrow, weekend, this-week, next-week, month, next-month, YYYY-MM-DD, a..b or >YYYY-MM-DD`},
+		{"date:past", 5, "date: use is:past"},
+		{"date:>2026-13-01", 5, `date: invalid date "2026-13-01", use YYYY-MM-DD`},
+		{"date:2026-10-20..2026-10-01", 5, "date: window ends (2026-10-01) before it starts (2026-10-20)"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.in)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%q) error = %v, want a SyntaxError", tt.in, err)
+			continue
+		}
+		if syntaxErr.Pos != tt.wantPos || syntaxErr.Msg != tt.wantMsg {
+			t.Errorf("Parse(%q) = %d %q, want %d %q", tt.in, syntaxErr.Pos, syntaxErr.Msg, tt.wantPos, tt.wantMsg)
+		}
+	}
+}
+
+func TestSyntaxErrorColumn(t *testing.T) {
+	_, err := Parse("rust title:")
+	if got, want := err.Error(), "col 12: title: needs a value"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"rust", "rust"},
+		{"Rust Meetup", "rust meetup"},
+		{`"impact hub" rust`, "impact hub rust"},
+		{"rust venue:hub go", "rust go"},
+		{"title:go rust", "rust"},
+		{"-rust go", "go"},
+		{"rust (go OR zig)", "rust"},
+		{"rust OR go", ""},
+		{"#rust is:free rsvp:>10", ""},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.in)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.in, err)
+		}
+		if got := q.Text(); got != tt.want {
+			t.Errorf("Parse(%q).Text() = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	var nilQuery *Query
+	if got := nilQuery.Text(); got != "" {
+		t.Errorf("nil Text() = %q", got)
+	}
+}
@@ -0,0 +1,60 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mcli/internal/types"
+	"os"
+)
+
+// File provides events from a local JSON array or JSON Lines file,
+// using the same fields as the mcli.d API
+type File struct {
+	name string
+	path string
+}
+
+func (s *File) Name() string { return s.name }
+
+// Events reads the file on every call so edits show up on refresh
+func (s *File) Events(ctx context.Context) (types.Events, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", s.path, err)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	var events types.Events
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &events); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for n := 1; scanner.Scan(); n++ {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var e types.Event
+			if err := json.Unmarshal(line, &e); err != nil {
+				return nil, fmt.Errorf("failed to parse %s line %d: %w", s.path, n, err)
+			}
+			events = append(events, e)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", s.path, err)
+		}
+	}
+
+	for i := range events {
+		if events[i].Source == "" {
+			events[i].Source = KindFile
+		}
+	}
+	return events, nil
+}
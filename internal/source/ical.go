@@ -0,0 +1,62 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mcli/internal/ical"
+	"mcli/internal/types"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ICal provides events from an iCalendar (.ics) feed URL or file
+type ICal struct {
+	name     string
+	location string
+	client   *http.Client
+}
+
+func (s *ICal) Name() string { return s.name }
+
+// Events downloads (or reads) and parses the calendar
+func (s *ICal) Events(ctx context.Context) (types.Events, error) {
+	if !isURL(s.location) {
+		f, err := os.Open(s.location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", s.location, err)
+		}
+		defer f.Close()
+		return ical.Parse(f, KindICal)
+	}
+
+	// webcal:// is https:// for calendar apps
+	feedURL := s.location
+	if strings.HasPrefix(feedURL, "webcal://") {
+		feedURL = "https://" + strings.TrimPrefix(feedURL, "webcal://")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request for %s: %w", feedURL, err)
+	}
+	req.Header.Set("Accept", "text/calendar")
+
+	client := s.client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s: %w", feedURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s returned %d %s", feedURL, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	// calendars can be large, but not unbounded
+	return ical.Parse(io.LimitReader(resp.Body, 16<<20), KindICal)
+}
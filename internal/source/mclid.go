@@ -0,0 +1,20 @@
+package source
+
+import (
+	"context"
+	"mcli/internal/api"
+	"mcli/internal/types"
+)
+
+// MCLID provides events from the mcli.d API
+type MCLID struct {
+	name   string
+	client *api.Client
+}
+
+func (s *MCLID) Name() string { return s.name }
+
+// Events returns every event mcli.d knows about
+func (s *MCLID) Events(ctx context.Context) (types.Events, error) {
+	return s.client.ListEvents(ctx)
+}
@@ -0,0 +1,255 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"mcli/internal/api"
+	"mcli/internal/types"
+	"mcli/internal/utils"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Provider kinds accepted in types.SourceSpec.Kind
+const (
+	KindMCLID = "mcli.d"
+	KindFile  = "file"
+	KindICal  = "ical"
+)
+
+// Kinds lists all provider kinds
+var Kinds = []string{KindMCLID, KindFile, KindICal}
+
+// EventSource provides events from one place: mcli.d, a file or a calendar feed
+type EventSource interface {
+	Name() string
+	Events(ctx context.Context) (types.Events, error)
+}
+
+// New builds the provider described by spec
+func New(spec types.SourceSpec, client *api.Client, hc *http.Client) (EventSource, error) {
+	if err := Validate(spec, false); err != nil {
+		return nil, err
+	}
+	switch spec.Kind {
+	case KindMCLID:
+		if client == nil || client.BaseURL == "" {
+			return nil, fmt.Errorf("source %q: api_base_url is not set", spec.Name)
+		}
+		return &MCLID{name: spec.Name, client: client}, nil
+	case KindFile:
+		return &File{name: spec.Name, path: ExpandHome(spec.Location)}, nil
+	default:
+		location := spec.Location
+		if !isURL(location) {
+			location = ExpandHome(location)
+		}
+		return &ICal{name: spec.Name, location: location, client: hc}, nil
+	}
+}
+
+// ExpandHome replaces a leading ~/ with the user's home directory
+func ExpandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
+
+// Validate checks a spec; remote restricts sources to URLs, for SSH users who
+// must not read files on the server.
+func Validate(spec types.SourceSpec, remote bool) error {
+	if spec.Name == "" {
+		return fmt.Errorf("source name is empty")
+	}
+	switch spec.Kind {
+	case KindMCLID:
+		return nil
+	case KindFile:
+		if spec.Location == "" {
+			return fmt.Errorf("source %q: file path is empty", spec.Name)
+		}
+		if remote {
+			return fmt.Errorf("source %q: file sources are not available over SSH", spec.Name)
+		}
+	case KindICal:
+		if spec.Location == "" {
+			return fmt.Errorf("source %q: calendar URL or path is empty", spec.Name)
+		}
+		if remote && !isURL(spec.Location) {
+			return fmt.Errorf("source %q: calendar must be an http(s) or webcal URL over SSH", spec.Name)
+		}
+	default:
+		return fmt.Errorf("source %q: unknown kind %q (available: %s)", spec.Name, spec.Kind, strings.Join(Kinds, ", "))
+	}
+	return nil
+}
+
+// CheckRemote validates a spec an SSH user enables. The server fetches feeds
+// from its own network, so calendars must come from one of allowedHosts
+// ("*.example.com" matches subdomains); none are allowed when it is empty.
+func CheckRemote(spec types.SourceSpec, allowedHosts []string) error {
+	if err := Validate(spec, true); err != nil {
+		return err
+	}
+	if spec.Kind != KindICal {
+		return nil
+	}
+	u, err := url.Parse(spec.Location)
+	if err != nil || u.Hostname() == "" {
+		return fmt.Errorf("source %q: invalid calendar URL %q", spec.Name, spec.Location)
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range allowedHosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed || (strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:])) {
+			return nil
+		}
+	}
+	return fmt.Errorf("source %q: %s is not an allowed calendar host over SSH", spec.Name, host)
+}
+
+// PublicHTTPClient only connects to public addresses. The check runs on the
+// resolved address of every connection, redirects included, so a host name
+// can't point it at loopback, link-local or private networks.
+func PublicHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip, err := netip.ParseAddr(host)
+			if err != nil || !isPublic(ip) {
+				return fmt.Errorf("refusing to connect to %s: not a public address", host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		// no proxy, it would be the address checked
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// carrier-grade NAT, shared like a private range
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+func isPublic(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// Resolve overlays per-user specs on the configured defaults, matching by name
+func Resolve(defaults, overrides []types.SourceSpec) []types.SourceSpec {
+	resolved := append([]types.SourceSpec{}, defaults...)
+	for _, o := range overrides {
+		replaced := false
+		for i := range resolved {
+			if resolved[i].Name == o.Name {
+				resolved[i] = o
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			resolved = append(resolved, o)
+		}
+	}
+	return resolved
+}
+
+// Merge joins event lists, dropping duplicates by ID and then by URL.
+// Earlier lists win, so put the most trusted source first.
+func Merge(lists ...types.Events) types.Events {
+	seenID := map[types.EventId]bool{}
+	seenURL := map[string]bool{}
+	merged := types.Events{}
+	for _, list := range lists {
+		for _, e := range list {
+			if seenID[e.ID] || (e.Url != "" && seenURL[e.Url]) {
+				continue
+			}
+			seenID[e.ID] = true
+			if e.Url != "" {
+				seenURL[e.Url] = true
+			}
+			merged = append(merged, e)
+		}
+	}
+	return merged
+}
+
+// FetchedMsg carries the merged events of the extra (non mcli.d) providers.
+// Errors lists providers that failed; their events are simply missing.
+type FetchedMsg struct {
+	Events types.Events
+	Errors []error
+}
+
+// FetchCmd queries all sources concurrently and merges their events
+func FetchCmd(ctx context.Context, sources []EventSource) tea.Cmd {
+	return func() tea.Msg {
+		return Fetch(ctx, sources)
+	}
+}
+
+// Fetch queries all sources concurrently and merges their events in the
+// order of sources
+func Fetch(ctx context.Context, sources []EventSource) FetchedMsg {
+	results := make([]types.Events, len(sources))
+	errs := make([]error, len(sources))
+	var wg sync.WaitGroup
+	for i, src := range sources {
+		wg.Add(1)
+		go func(i int, src EventSource) {
+			defer wg.Done()
+			events, err := src.Events(ctx)
+			if err != nil {
+				utils.Logger.Error("source fetch failed", "source", src.Name(), "err", err)
+				errs[i] = fmt.Errorf("%s: %w", src.Name(), err)
+				return
+			}
+			results[i] = events
+		}(i, src)
+	}
+	wg.Wait()
+
+	msg := FetchedMsg{Events: Merge(results...)}
+	for _, err := range errs {
+		if err != nil {
+			msg.Errors = append(msg.Errors, err)
+		}
+	}
+	return msg
+}
+
+func isURL(location string) bool {
+	for _, prefix := range []string{"http://", "https://", "webcal://"} {
+		if strings.HasPrefix(location, prefix) {
+			return true
+		}
+	}
+	return false
+}
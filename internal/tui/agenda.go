@@ -0,0 +1,232 @@
+package tui
+
+import (
+	"fmt"
+	"mcli/internal/api"
+	"mcli/internal/tui/styles"
+	"mcli/internal/types"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/reflow/truncate"
+)
+
+// overlapGuess is how long an event without an end time is assumed to last
+// when looking for overlaps
+const overlapGuess = time.Hour
+
+// Agenda lists events under day headers with their start and end times.
+// It shares the table's cursor, an index into the displayed events.
+type Agenda struct {
+	Width  int
+	Height int
+
+	lines   []string // rendered without the cursor highlight
+	eventAt []int    // event index per line, -1 for headers and gaps
+	lineOf  []int    // first line per event index
+	cursor  int
+	offset  int // first visible line
+}
+
+// agendaItem is an event with its times resolved in the user's zone
+type agendaItem struct {
+	index    int
+	event    types.Event
+	start    time.Time
+	end      time.Time
+	allDay   bool
+	ok       bool // the date could be parsed
+	overlaps bool
+}
+
+// NewAgenda returns an empty agenda
+func NewAgenda() Agenda {
+	return Agenda{Width: 20, Height: 20}
+}
+
+// SetSize sets the area the agenda renders into
+func (a *Agenda) SetSize(width, height int) {
+	a.Width, a.Height = width, height
+	a.SetCursor(a.cursor)
+}
+
+// SetEvents lays out events, which must be sorted by date as displayed
+func (a *Agenda) SetEvents(events []types.Event, rc RowContext) {
+	items := agendaItems(events, rc.Zone)
+	markOverlaps(items)
+
+	a.lines, a.eventAt, a.lineOf = nil, nil, make([]int, len(events))
+	now := time.Now()
+	lastDay := ""
+	for _, it := range items {
+		day := "Unknown date"
+		if it.ok {
+			day = dayHeader(it.start, now)
+		}
+		if day != lastDay {
+			if lastDay != "" {
+				a.add("", -1)
+			}
+			header := lipgloss.NewStyle().Bold(true).Foreground(styles.DefaultTheme.TableHeader).Render(day)
+			a.add(header, -1)
+			lastDay = day
+		}
+		a.lineOf[it.index] = len(a.lines)
+		a.add(a.renderItem(it, rc, now), it.index)
+	}
+	a.SetCursor(a.cursor)
+}
+
+func (a *Agenda) add(line string, event int) {
+	a.lines = append(a.lines, line)
+	a.eventAt = append(a.eventAt, event)
+}
+
+// SetCursor selects the event at index i and scrolls it into view
+func (a *Agenda) SetCursor(i int) {
+	if i >= len(a.lineOf) {
+		i = len(a.lineOf) - 1
+	}
+	if i < 0 {
+		a.cursor, a.offset = 0, 0
+		return
+	}
+	a.cursor = i
+	line := a.lineOf[i]
+	// keep the day header in sight when selecting a day's first event
+	top := line
+	if top > 0 && a.eventAt[top-1] == -1 && a.lines[top-1] != "" {
+		top--
+	}
+	if top < a.offset {
+		a.offset = top
+	}
+	if line >= a.offset+a.Height {
+		a.offset = line - a.Height + 1
+	}
+}
+
+// View renders the visible part of the agenda
+func (a Agenda) View() string {
+	if len(a.lines) == 0 {
+		return ""
+	}
+	selected := lipgloss.NewStyle().
+		Foreground(styles.DefaultTheme.TableRowSelectedForeground).
+		Background(styles.DefaultTheme.TableRowSelectedBackground).
+		Width(a.Width)
+	end := min(a.offset+a.Height, len(a.lines))
+	visible := make([]string, 0, a.Height)
+	for l := a.offset; l < end; l++ {
+		line := a.lines[l]
+		if a.eventAt[l] == a.cursor {
+			// drop the row's own styling so the highlight covers it evenly
+			line = selected.Render(ansi.Strip(line))
+		}
+		visible = append(visible, line)
+	}
+	for len(visible) < a.Height {
+		visible = append(visible, "")
+	}
+	return lipgloss.NewStyle().Width(a.Width).Render(strings.Join(visible, "\n"))
+}
+
+func (a Agenda) renderItem(it agendaItem, rc RowContext, now time.Time) string {
+	when := "  ?  "
+	switch {
+	case it.allDay:
+		when = "all day"
+	case it.ok && !it.end.IsZero():
+		when = fmt.Sprintf("%s–%s", it.start.Format("15:04"), it.end.Format("15:04"))
+	case it.ok:
+		when = it.start.Format("15:04")
+	}
+
+	marks := []rune("   ")
+	if rc.IsBookmarked != nil && rc.IsBookmarked(it.event.ID) {
+		marks[0] = '★'
+	}
+	if rc.IsRead != nil && rc.IsRead(it.event.ID) {
+		marks[1] = '·'
+	}
+	if it.overlaps {
+		marks[2] = '⇆'
+	}
+
+	line := fmt.Sprintf(" %-11s %s %s", when, string(marks), it.event.Title)
+	if venue := it.event.VenueName; venue != "" {
+		line += "  @ " + venue
+	}
+	if rc.Distance != nil {
+		if d := rc.Distance(it.event); d != "" {
+			line += " (" + d + ")"
+		}
+	}
+	line = truncate.StringWithTail(line, uint(max(a.Width, 1)), "…")
+	if isPast(it.event, now, rc.Zone) {
+		line = styles.PastStyle.Render(line)
+	}
+	return line
+}
+
+// agendaItems resolves the times of events in zone
+func agendaItems(events []types.Event, zone *time.Location) []agendaItem {
+	items := make([]agendaItem, len(events))
+	for i, e := range events {
+		it := agendaItem{index: i, event: e}
+		start, allDay, err := api.ParseDateTimeIn(e.DateTime, zone)
+		if err == nil {
+			it.ok, it.allDay = true, allDay
+			it.start = api.InZone(start, zone)
+			if end, ok := eventEnd(e, start, zone); ok && !allDay {
+				it.end = api.InZone(end, zone)
+			}
+		}
+		items[i] = it
+	}
+	return items
+}
+
+// markOverlaps flags timed events on the same day whose times intersect.
+// Events without an end are assumed to last overlapGuess.
+func markOverlaps(items []agendaItem) {
+	end := func(it agendaItem) time.Time {
+		if it.end.After(it.start) {
+			return it.end
+		}
+		return it.start.Add(overlapGuess)
+	}
+	for i := range items {
+		if !items[i].ok || items[i].allDay {
+			continue
+		}
+		for j := i + 1; j < len(items); j++ {
+			if !items[j].ok || items[j].allDay {
+				continue
+			}
+			// sorted by start, nothing later can overlap i
+			if !items[j].start.Before(end(items[i])) {
+				break
+			}
+			items[i].overlaps, items[j].overlaps = true, true
+		}
+	}
+}
+
+// dayHeader renders a day like "Sat 18 Oct", naming today and tomorrow
+func dayHeader(t, now time.Time) string {
+	header := t.Format("Mon 02 Jan")
+	if t.Year() != now.Year() {
+		header = t.Format("Mon 02 Jan 2006")
+	}
+	today := now.In(t.Location())
+	switch {
+	case sameDay(t, today):
+		header += " · today"
+	case sameDay(t, today.AddDate(0, 0, 1)):
+		header += " · tomorrow"
+	}
+	return header
+}
@@ -0,0 +1,212 @@
+package tui
+
+import (
+	"fmt"
+	"mcli/internal/api"
+	"mcli/internal/tui/styles"
+	"mcli/internal/types"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+)
+
+const dayKeyLayout = "2006-01-02"
+
+// Calendar draws a month grid with the number of events per day and lists
+// the events of the selected day below it. Event indices refer to the slice
+// passed to SetEvents, so they can be used as the table cursor.
+type Calendar struct {
+	Width  int
+	Height int
+
+	zone     *time.Location
+	events   []types.Event
+	byDay    map[string][]int // day key -> event indices, in date order
+	selected time.Time        // midnight of the selected day in zone
+	eventPos int              // selected event within the day
+}
+
+// NewCalendar returns a calendar on today
+func NewCalendar() Calendar {
+	c := Calendar{Width: 20, Height: 20, zone: time.Local, byDay: map[string][]int{}}
+	c.selected = api.StartOfDay(time.Now(), c.zone)
+	return c
+}
+
+// SetSize sets the area the calendar renders into
+func (c *Calendar) SetSize(width, height int) {
+	c.Width, c.Height = width, height
+}
+
+// SetEvents buckets events by their day in zone. The selected day is kept.
+func (c *Calendar) SetEvents(events []types.Event, zone *time.Location) {
+	if zone == nil {
+		zone = time.Local
+	}
+	if zone != c.zone {
+		y, m, d := c.selected.Date()
+		c.selected = time.Date(y, m, d, 0, 0, 0, 0, zone)
+		c.zone = zone
+	}
+	c.events = events
+	c.byDay = map[string][]int{}
+	for i, e := range events {
+		start, _, err := api.ParseDateTimeIn(e.DateTime, zone)
+		if err != nil {
+			continue
+		}
+		key := start.In(zone).Format(dayKeyLayout)
+		c.byDay[key] = append(c.byDay[key], i)
+	}
+	c.clampEvent()
+}
+
+// Move selects the day delta days away, following into other months
+func (c *Calendar) Move(days int) {
+	c.selected = c.selected.AddDate(0, 0, days)
+	c.eventPos = 0
+}
+
+// MoveMonth selects the same day (or the month's last) n months away
+func (c *Calendar) MoveMonth(n int) {
+	y, m, d := c.selected.Date()
+	first := time.Date(y, m+time.Month(n), 1, 0, 0, 0, 0, c.zone)
+	last := first.AddDate(0, 1, -1).Day()
+	c.selected = time.Date(first.Year(), first.Month(), min(d, last), 0, 0, 0, 0, c.zone)
+	c.eventPos = 0
+}
+
+// NextEvent moves the selection delta events within the selected day, wrapping
+func (c *Calendar) NextEvent(delta int) {
+	n := len(c.dayEvents())
+	if n == 0 {
+		return
+	}
+	c.eventPos = ((c.eventPos+delta)%n + n) % n
+}
+
+// Select jumps to the day of the event at index i and selects it
+func (c *Calendar) Select(i int) {
+	if i < 0 || i >= len(c.events) {
+		return
+	}
+	start, _, err := api.ParseDateTimeIn(c.events[i].DateTime, c.zone)
+	if err != nil {
+		return
+	}
+	c.selected = api.StartOfDay(start, c.zone)
+	for pos, idx := range c.dayEvents() {
+		if idx == i {
+			c.eventPos = pos
+		}
+	}
+}
+
+// Selected returns the index of the selected event, false on a day without events
+func (c Calendar) Selected() (int, bool) {
+	day := c.dayEvents()
+	if len(day) == 0 {
+		return 0, false
+	}
+	return day[c.eventPos], true
+}
+
+func (c Calendar) dayEvents() []int {
+	return c.byDay[c.selected.Format(dayKeyLayout)]
+}
+
+func (c *Calendar) clampEvent() {
+	if n := len(c.dayEvents()); c.eventPos >= n {
+		c.eventPos = max(n-1, 0)
+	}
+}
+
+// View renders the month grid followed by the selected day's events
+func (c Calendar) View() string {
+	cellWidth := max(c.Width/7, 4)
+	header := lipgloss.NewStyle().Bold(true).Foreground(styles.DefaultTheme.TableHeader)
+	selected := lipgloss.NewStyle().
+		Foreground(styles.DefaultTheme.TableRowSelectedForeground).
+		Background(styles.DefaultTheme.TableRowSelectedBackground)
+	cell := lipgloss.NewStyle().Width(cellWidth)
+
+	lines := []string{
+		header.Render(c.selected.Format("January 2006")) + styles.PastStyle.Render("   pgup/pgdown: month"),
+	}
+	var weekdays []string
+	for _, wd := range []string{"Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"} {
+		weekdays = append(weekdays, cell.Render(wd))
+	}
+	lines = append(lines, header.Render(strings.Join(weekdays, "")))
+
+	// weeks start on Monday
+	first := time.Date(c.selected.Year(), c.selected.Month(), 1, 0, 0, 0, 0, c.zone)
+	offset := (int(first.Weekday()) + 6) % 7
+	today := api.StartOfDay(time.Now(), c.zone)
+	day := first.AddDate(0, 0, -offset)
+	for week := 0; week < 6; week++ {
+		var row []string
+		for wd := 0; wd < 7; wd++ {
+			text := fmt.Sprintf("%2d", day.Day())
+			if n := len(c.byDay[day.Format(dayKeyLayout)]); n > 0 {
+				text += fmt.Sprintf(" •%d", n)
+				if cellWidth < 6 {
+					text = fmt.Sprintf("%2d•%d", day.Day(), n)
+				}
+			}
+			text = truncate.String(text, uint(cellWidth))
+			style := cell
+			switch {
+			case day.Equal(c.selected):
+				style = cell.Inherit(selected)
+			case day.Month() != c.selected.Month():
+				style = cell.Inherit(styles.PastStyle)
+			case day.Equal(today):
+				style = cell.Bold(true).Underline(true)
+			}
+			row = append(row, style.Render(text))
+			day = day.AddDate(0, 0, 1)
+		}
+		lines = append(lines, strings.Join(row, ""))
+		if day.Month() != c.selected.Month() {
+			break
+		}
+	}
+
+	lines = append(lines, "", header.Render(c.selected.Format("Mon 02 Jan")))
+	dayEvents := c.dayEvents()
+	if len(dayEvents) == 0 {
+		lines = append(lines, styles.PastStyle.Render("  no events"))
+	}
+	// scroll the day's list to keep the selected event in sight
+	room := max(c.Height-len(lines)-1, 1)
+	top := max(0, c.eventPos-room+1)
+	for pos := top; pos < len(dayEvents) && pos < top+room; pos++ {
+		e := c.events[dayEvents[pos]]
+		when := "all day"
+		if start, allDay, err := api.ParseDateTimeIn(e.DateTime, c.zone); err == nil && !allDay {
+			when = start.In(c.zone).Format("15:04")
+		}
+		line := fmt.Sprintf("  %-7s %s", when, e.Title)
+		if e.VenueName != "" {
+			line += "  @ " + e.VenueName
+		}
+		line = truncate.StringWithTail(line, uint(max(c.Width, 1)), "…")
+		if pos == c.eventPos {
+			line = selected.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, styles.PastStyle.Render("  hjkl/arrows: day · tab: next event"))
+
+	if len(lines) > c.Height && c.Height > 0 {
+		lines = lines[:c.Height]
+	}
+	// cut rather than wrap lines wider than the view
+	for i, line := range lines {
+		lines[i] = truncate.String(line, uint(max(c.Width, 1)))
+	}
+	return lipgloss.NewStyle().Width(c.Width).Height(c.Height).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"mcli/internal/api"
+	"mcli/internal/columns"
+	"mcli/internal/types"
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// columnDef describes a column. Fixed columns have a width; flexible ones
+// get at least min and share the rest of the table by weight.
+type columnDef struct {
+	title    string
+	width    int // 0 for flexible
+	min      int
+	priority int // narrow tables drop the lowest first, 0 is never dropped
+	cell     func(e types.Event, rc RowContext) string
+}
+
+var columnDefs = map[string]columnDef{
+	columns.Icon:  {title: "🚀", width: 2, priority: 9, cell: iconCell},
+	columns.Event: {title: "Event", min: 20, cell: func(e types.Event, _ RowContext) string { return e.Title }},
+	columns.Mark: {title: "☆", width: 2, priority: 8, cell: func(e types.Event, rc RowContext) string {
+		if rc.IsBookmarked != nil && rc.IsBookmarked(e.ID) {
+			return "★"
+		}
+		return " "
+	}},
+	columns.Location: {title: "Location", min: 10, priority: 3, cell: func(e types.Event, _ RowContext) string { return e.VenueAddress }},
+	columns.Price: {title: "Price", width: 8, priority: 5, cell: func(e types.Event, _ RowContext) string {
+		// blank when the backend doesn't know the price
+		if e.Price == nil {
+			return ""
+		}
+		return e.Price.String()
+	}},
+	columns.Distance: {title: "Dist", width: 7, priority: 4, cell: func(e types.Event, rc RowContext) string { // "~1234km"
+		if rc.Distance == nil {
+			return ""
+		}
+		return rc.Distance(e)
+	}},
+	columns.In: {title: "In", width: 8, priority: 10, cell: func(e types.Event, rc RowContext) string {
+		_, _, in, err := api.ParseAndCompareDateTime(e.DateTime, rc.Zone)
+		if err != nil {
+			// kept by SortEvents so it isn't lost, but we can't say when
+			return "⚠ date?"
+		}
+		return in
+	}},
+	columns.Date: {title: "Date", width: 16, priority: 7, cell: func(e types.Event, rc RowContext) string {
+		start, allDay, err := api.ParseDateTimeIn(e.DateTime, rc.Zone)
+		if err != nil {
+			return "⚠ date?"
+		}
+		start = api.InZone(start, rc.Zone)
+		if allDay {
+			return start.Format("Mon 02 Jan")
+		}
+		return start.Format("Mon 02 Jan 15:04")
+	}},
+	columns.Rsvps: {title: "Going", width: 7, priority: 5, cell: func(e types.Event, _ RowContext) string {
+		// most sources don't report RSVPs, 0 is as good as unknown
+		if e.RsvpsCount == 0 {
+			return ""
+		}
+		return strconv.Itoa(e.RsvpsCount)
+	}},
+	columns.Venue:  {title: "Venue", min: 10, priority: 3, cell: func(e types.Event, _ RowContext) string { return e.VenueName }},
+	columns.Source: {title: "Source", width: 8, priority: 2, cell: func(e types.Event, _ RowContext) string { return e.Source }},
+	columns.Status: {title: "Status", width: 10, priority: 1, cell: func(e types.Event, _ RowContext) string { return e.Status }},
+	columns.Type:   {title: "Type", width: 10, priority: 1, cell: func(e types.Event, _ RowContext) string { return e.Type }},
+}
+
+func iconCell(e types.Event, rc RowContext) string {
+	// Show · instead of source icon for read events
+	if rc.IsRead != nil && rc.IsRead(e.ID) {
+		return "·"
+	}
+	if e.Source == "luma" {
+		return "✦︎"
+	}
+	return "☘️"
+}
+
+// layoutColumns sizes layout to width. Columns that don't fit are given
+// width 0, which the table skips, lowest priority (then rightmost) first.
+func layoutColumns(layout []columns.Column, width int, showDistance bool) []table.Column {
+	shown := make([]bool, len(layout))
+	for i, c := range layout {
+		shown[i] = c.Name != columns.Distance || showDistance
+	}
+	// every shown column is padded by one cell on each side
+	need := func() (fixed, weights int) {
+		for i, c := range layout {
+			if !shown[i] {
+				continue
+			}
+			def := columnDefs[c.Name]
+			fixed += def.width + def.min + 2
+			if def.width == 0 {
+				weights += c.Weight
+			}
+		}
+		return fixed, weights
+	}
+	for {
+		fixed, _ := need()
+		if fixed <= width {
+			break
+		}
+		drop := -1
+		for i, c := range layout {
+			p := columnDefs[c.Name].priority
+			if shown[i] && p > 0 && (drop < 0 || p <= columnDefs[layout[drop].Name].priority) {
+				drop = i
+			}
+		}
+		if drop < 0 {
+			break
+		}
+		shown[drop] = false
+	}
+
+	fixed, weights := need()
+	extra := max(0, width-fixed)
+	columns := make([]table.Column, len(layout))
+	for i, c := range layout {
+		def := columnDefs[c.Name]
+		columns[i].Title = def.title
+		switch {
+		case !shown[i]:
+		case def.width > 0:
+			columns[i].Width = def.width
+		default:
+			columns[i].Width = def.min + extra*c.Weight/weights
+		}
+	}
+	return columns
+}
@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mcli/internal/api"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DescribeError turns an error from the api package into a short, user facing message
+func DescribeError(err error) string {
+	var openErr *api.OpenError
+	var apiErr *api.APIError
+	var decodeErr *api.DecodeError
+	var urlErr *url.Error
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "Request cancelled"
+	case errors.As(err, &openErr):
+		return fmt.Sprintf("mcli.d looks down, requests paused for %s", openErr.RetryIn.Round(time.Second))
+	case errors.As(err, &apiErr):
+		var msg string
+		switch {
+		case apiErr.StatusCode == http.StatusNotFound:
+			msg = fmt.Sprintf("mcli.d has no %s endpoint, check api_base_url", apiErr.Endpoint)
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			msg = "mcli.d is rate limiting requests"
+		case apiErr.StatusCode >= 500:
+			msg = fmt.Sprintf("mcli.d failed to serve %s (%d)", apiErr.Endpoint, apiErr.StatusCode)
+		default:
+			msg = fmt.Sprintf("mcli.d rejected %s (%d %s)", apiErr.Endpoint, apiErr.StatusCode, http.StatusText(apiErr.StatusCode))
+		}
+		if apiErr.Message != "" {
+			msg += ": " + apiErr.Message
+		}
+		return msg
+	case errors.As(err, &decodeErr):
+		return fmt.Sprintf("mcli.d sent an unexpected %s response", decodeErr.Endpoint)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "mcli.d did not answer in time"
+	case errors.As(err, &urlErr):
+		return fmt.Sprintf("Could not reach mcli.d at %s", urlErr.URL)
+	default:
+		return err.Error()
+	}
+}
+
+// ErrorView renders a full screen error with a retry hint, used when there is nothing else to show
+func ErrorView(err error, retryKey string) string {
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9")).Render("⚠ " + DescribeError(err))
+	hint := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(
+		fmt.Sprintf("Press '%s' to retry or ':' for commands", retryKey))
+	return lipgloss.JoinVertical(lipgloss.Left, title, "", hint)
+}
+
+// NetStatus renders retry progress and breaker state for the status bar
+func NetStatus(retry *api.RetryEvent, breaker api.BreakerState) string {
+	if retry != nil {
+		return fmt.Sprintf("⟳ retry %d/%d in %s", retry.Attempt, retry.MaxAttempts-1, retry.Delay.Round(100*time.Millisecond))
+	}
+	switch breaker {
+	case api.BreakerOpen:
+		return "⚡ backend paused"
+	case api.BreakerHalfOpen:
+		return "⚡ backend probing"
+	}
+	return ""
+}
+
+// JobProgress describes a scrape job for the command prompt
+func JobProgress(job api.FetchJob, err error) string {
+	if err != nil {
+		return fmt.Sprintf("Fetching %s failed: %s", job.Location, DescribeError(err))
+	}
+	found := ""
+	if job.NewEvents > 0 {
+		found = fmt.Sprintf(" %d new events", job.NewEvents)
+	}
+	switch job.Status {
+	case api.JobFailed:
+		msg := fmt.Sprintf("Scraping %s failed", job.Location)
+		if job.Message != "" {
+			msg += ": " + job.Message
+		}
+		return msg
+	case api.JobDone:
+		if found == "" {
+			found = " no new events"
+		}
+		return fmt.Sprintf("Scraped %s,%s", job.Location, found)
+	case api.JobTimedOut:
+		msg := fmt.Sprintf("Scraping %s: %s", job.Location, job.Message)
+		if found != "" {
+			msg += "," + found + " so far"
+		}
+		return msg
+	default:
+		return fmt.Sprintf("scraping %s…%s", job.Location, found)
+	}
+}
@@ -1,24 +1,28 @@
 package tui
 
 import (
+	"mcli/internal/query"
 	"mcli/internal/types"
-	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 type Filter struct {
 	Input   textinput.Model
-	Text    string
+	Text    string       // the last valid query
+	Query   *query.Query // Text parsed
+	Err     error        // why the input isn't a valid query, nil when it is
 	visible bool
 }
 
 func NewFilter() Filter {
 
 	filterInput := textinput.New()
-	filterInput.Placeholder = "Filter by title, location, description..."
-	filterInput.CharLimit = 50
+	filterInput.Placeholder = "Filter: rust venue:\"impact hub\" rsvp:>50 date:this-week -source:luma"
+	filterInput.CharLimit = 200
 	filterInput.Width = 50
 
 	return Filter{
@@ -41,25 +45,41 @@ func (f *Filter) View() string {
 		return ""
 	}
 	// TODO: stylize the input field
+	if f.Err != nil {
+		return f.Input.View() + "  " + errorStyle.Render("✗ "+f.Err.Error())
+	}
 	return f.Input.View()
 }
+
+var errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+
+// SetText parses text as the filter query. Invalid text only sets Err, the
+// previous query stays in effect while it is being typed.
+func (f *Filter) SetText(text string) {
+	q, err := query.Parse(text)
+	if err != nil {
+		f.Err = err
+		return
+	}
+	f.Text, f.Query, f.Err = text, q, nil
+}
 func (f *Filter) Update(msg tea.Msg) (Filter, tea.Cmd) {
 	var cmd tea.Cmd
 	f.Input, cmd = f.Input.Update(msg)
 	return *f, cmd
 }
 
-// FilterEvents returns only those events whose title, location or description
-// contains the query (case-insensitive).
-func FilterEvents(events []types.Event, query string) []types.Event {
-	q := strings.ToLower(query)
-	var filtered []types.Event
-	for _, e := range events {
-		if strings.Contains(strings.ToLower(e.Title), q) ||
-			strings.Contains(strings.ToLower(e.Location.VenueAddress), q) ||
-			strings.Contains(strings.ToLower(e.Description), q) {
-			filtered = append(filtered, e)
-		}
-	}
-	return filtered
+// Apply returns the events matching the last valid query, with dates read in zone
+func (f *Filter) Apply(events []types.Event, zone *time.Location) []types.Event {
+	return f.Query.Filter(events, query.Env{Now: time.Now(), Zone: zone})
+}
+
+// ApplyScored is Apply that also scores the matches, for RankEvents
+func (f *Filter) ApplyScored(events []types.Event, zone *time.Location) ([]types.Event, map[types.EventId]int) {
+	return f.Query.FilterScores(events, query.Env{Now: time.Now(), Zone: zone})
+}
+
+// RankEvents orders events by the scores of ApplyScored, best first
+func RankEvents(events []types.Event, scores map[types.EventId]int) []types.Event {
+	return query.Rank(events, scores)
 }
@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"mcli/internal/tui/styles"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// MatchFn returns the rune indexes of s matched by the filter, in order
+type MatchFn func(s string) []int
+
+// highlight renders the runes of s at positions with match on top of base
+func highlight(s string, positions []int, base lipgloss.Style) string {
+	if len(positions) == 0 {
+		return base.Render(s)
+	}
+	match := base.Inherit(styles.MatchStyle)
+	var b, run strings.Builder
+	matched, next := false, 0
+	flush := func() {
+		if run.Len() == 0 {
+			return
+		}
+		if matched {
+			b.WriteString(match.Render(run.String()))
+		} else {
+			b.WriteString(base.Render(run.String()))
+		}
+		run.Reset()
+	}
+	for i, r := range []rune(s) {
+		for next < len(positions) && positions[next] < i {
+			next++
+		}
+		isMatch := next < len(positions) && positions[next] == i
+		if isMatch != matched {
+			flush()
+			matched = isMatch
+		}
+		run.WriteRune(r)
+	}
+	flush()
+	return b.String()
+}
+
+// highlightCell is highlight for a table cell of width. Like dimRow, it
+// shortens the text first by the escape codes of every highlighted run,
+// since the table counts them as text when truncating; in narrow cells
+// only the first runs are highlighted.
+func highlightCell(cell string, positions []int, width int) string {
+	overhead := runewidth.StringWidth(styles.MatchStyle.Render("x")) - 1
+	for runs := countRuns(positions); runs > 0; runs-- {
+		budget := width - runs*overhead
+		if budget < 4 {
+			continue
+		}
+		shown := runewidth.Truncate(cell, budget, "…")
+		visible := len([]rune(shown))
+		if shown != cell {
+			visible-- // never highlight the ellipsis
+		}
+		kept := firstRuns(positions, runs, visible)
+		// runs cut off by the truncation leave room for more text
+		if countRuns(kept) < runs {
+			continue
+		}
+		return highlight(shown, kept, lipgloss.NewStyle())
+	}
+	return cell
+}
+
+// countRuns counts the stretches of consecutive positions
+func countRuns(positions []int) int {
+	runs := 0
+	for i, p := range positions {
+		if i == 0 || positions[i-1] != p-1 {
+			runs++
+		}
+	}
+	return runs
+}
+
+// firstRuns keeps the positions of the first n runs that are below limit
+func firstRuns(positions []int, n, limit int) []int {
+	runs := 0
+	for i, p := range positions {
+		if i == 0 || positions[i-1] != p-1 {
+			runs++
+		}
+		if p >= limit || runs > n {
+			return positions[:i]
+		}
+	}
+	return positions
+}
@@ -0,0 +1,183 @@
+package tui
+
+import (
+	"fmt"
+	"mcli/internal/tui/styles"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+)
+
+// PickerItem is a saved search as listed in the picker
+type PickerItem struct {
+	Name   string
+	Detail string // what the search applies
+}
+
+// Picker lists saved searches in a box drawn over the event list, where
+// they can be applied, renamed and deleted
+type Picker struct {
+	Width  int
+	Height int
+	Input  textinput.Model // new name while renaming
+
+	items    []PickerItem
+	cursor   int
+	offset   int // first visible item
+	visible  bool
+	renaming bool
+	deleting bool // waiting for y to confirm
+}
+
+// NewPicker returns a hidden picker
+func NewPicker() Picker {
+	input := textinput.New()
+	input.Prompt = "Rename to: "
+	input.CharLimit = 60
+	return Picker{Width: 20, Height: 20, Input: input}
+}
+
+func (p *Picker) IsVisible() bool {
+	return p.visible
+}
+
+// Open shows items with the first one selected
+func (p *Picker) Open(items []PickerItem) {
+	p.visible, p.renaming, p.deleting = true, false, false
+	p.cursor, p.offset = 0, 0
+	p.SetItems(items)
+}
+
+func (p *Picker) Close() {
+	p.visible, p.renaming, p.deleting = false, false, false
+	p.Input.Blur()
+}
+
+// SetItems replaces the list, keeping the cursor in range
+func (p *Picker) SetItems(items []PickerItem) {
+	p.items = items
+	p.Move(0)
+}
+
+// SetSize sets the area the picker is centered in
+func (p *Picker) SetSize(width, height int) {
+	p.Width, p.Height = width, height
+	p.Move(0)
+}
+
+// Move moves the cursor by delta items, scrolling to keep it visible
+func (p *Picker) Move(delta int) {
+	p.cursor = max(0, min(p.cursor+delta, len(p.items)-1))
+	rows := p.rows()
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	} else if p.cursor >= p.offset+rows {
+		p.offset = p.cursor - rows + 1
+	}
+}
+
+// Select moves the cursor to the item called name, if any
+func (p *Picker) Select(name string) {
+	for i, it := range p.items {
+		if it.Name == name {
+			p.Move(i - p.cursor)
+			return
+		}
+	}
+}
+
+// Selected returns the name under the cursor
+func (p *Picker) Selected() (string, bool) {
+	if p.cursor < 0 || p.cursor >= len(p.items) {
+		return "", false
+	}
+	return p.items[p.cursor].Name, true
+}
+
+// StartRename asks for a new name for the selected item
+func (p *Picker) StartRename() {
+	name, ok := p.Selected()
+	if !ok {
+		return
+	}
+	p.renaming = true
+	p.Input.SetValue(name)
+	p.Input.CursorEnd()
+	p.Input.Focus()
+}
+
+func (p *Picker) StopRename() {
+	p.renaming = false
+	p.Input.Blur()
+}
+
+func (p *Picker) IsRenaming() bool {
+	return p.renaming
+}
+
+// ConfirmDelete asks to confirm deleting the selected item, or stops asking
+func (p *Picker) ConfirmDelete(ask bool) {
+	_, ok := p.Selected()
+	p.deleting = ask && ok
+}
+
+func (p *Picker) IsDeleting() bool {
+	return p.deleting
+}
+
+// rows is how many items fit in the box, between its title and footer
+func (p *Picker) rows() int {
+	return max(1, p.Height-8)
+}
+
+func (p Picker) View() string {
+	if !p.visible {
+		return ""
+	}
+	width := max(20, min(p.Width-4, 70))
+	title := lipgloss.NewStyle().Bold(true).Foreground(styles.DefaultTheme.TableHeader).Render("Saved searches")
+	selected := lipgloss.NewStyle().
+		Foreground(styles.DefaultTheme.TableRowSelectedForeground).
+		Background(styles.DefaultTheme.TableRowSelectedBackground)
+	faint := lipgloss.NewStyle().Foreground(styles.DefaultTheme.FaintBorder)
+
+	lines := []string{title, ""}
+	if len(p.items) == 0 {
+		lines = append(lines, faint.Render("No saved searches, save one with :save-search <name>"))
+	}
+	nameWidth := 0
+	for _, it := range p.items {
+		nameWidth = max(nameWidth, lipgloss.Width(it.Name))
+	}
+	end := min(len(p.items), p.offset+p.rows())
+	for i := p.offset; i < end; i++ {
+		it := p.items[i]
+		name := it.Name + strings.Repeat(" ", nameWidth-lipgloss.Width(it.Name))
+		line := truncate.StringWithTail(fmt.Sprintf("%s  %s", name, faint.Render(it.Detail)), uint(width-2), "…")
+		if i == p.cursor {
+			line = selected.Render(truncate.StringWithTail(fmt.Sprintf("%s  %s", name, it.Detail), uint(width-2), "…"))
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "")
+	switch {
+	case p.renaming:
+		lines = append(lines, p.Input.View())
+	case p.deleting:
+		name, _ := p.Selected()
+		lines = append(lines, fmt.Sprintf("Delete %q? y/n", name))
+	default:
+		lines = append(lines, faint.Render("enter apply · r rename · d delete · esc close"))
+	}
+
+	box := lipgloss.NewStyle().
+		Width(width).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("63")).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(p.Width, p.Height, lipgloss.Center, lipgloss.Center, box)
+}
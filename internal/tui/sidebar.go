@@ -7,6 +7,7 @@ import (
 	"mcli/internal/types"
 	"mcli/internal/utils"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -20,6 +21,7 @@ type Sidebar struct {
 	Viewport viewport.Model
 	Width    int
 	Height   int
+	Match    MatchFn // highlights what the filter matched, nil for none
 }
 
 func NewSidebar() Sidebar {
@@ -44,9 +46,10 @@ func (s *Sidebar) ToggleSidebarView() {
 	s.visible = !s.visible
 }
 
-func (s *Sidebar) UpdateSidebarContent(event types.Event, height int) {
+// UpdateSidebarContent shows event, with times in zone (nil for the local zone)
+func (s *Sidebar) UpdateSidebarContent(event types.Event, height int, zone *time.Location) {
 
-	title := lipgloss.NewStyle().Bold(true).Foreground(styles.DefaultTheme.TableHeader).Render(event.Title)
+	title := s.highlight(event.Title, lipgloss.NewStyle().Bold(true).Foreground(styles.DefaultTheme.TableHeader))
 
 	description, _ := glamour.Render(event.Description, "dark")
 	if event.Description == "" {
@@ -55,14 +58,32 @@ func (s *Sidebar) UpdateSidebarContent(event types.Event, height int) {
 
 	url := lipgloss.NewStyle().Bold(true).Foreground(styles.DefaultTheme.SidebarUrl).Render(event.Url)
 
-	parsedTime, _, _, _ := api.ParseAndCompareDateTime(event.DateTime)
-	date := lipgloss.NewStyle().Bold(true).Foreground(styles.DefaultTheme.SidebarDateTime).Render(api.UTC2Local(parsedTime).String())
+	parsedTime, allDay, err := api.ParseDateTimeIn(event.DateTime, zone)
+	start := api.InZone(parsedTime, zone)
+	when := start.String()
+	switch {
+	case err != nil:
+		when = fmt.Sprintf("⚠ unrecognized date %q", event.DateTime)
+	case allDay:
+		// midnight in the zone the date was read in
+		when = start.Format("Mon, 02 Jan 2006") + " (all day)"
+	default:
+		if end, ok := eventEnd(event, parsedTime, zone); ok {
+			end = api.InZone(end, zone)
+			if sameDay(start, end) {
+				when = fmt.Sprintf("%s → %s", when, end.Format("15:04"))
+			} else {
+				when = fmt.Sprintf("%s → %s", when, end.String())
+			}
+		}
+	}
+	date := lipgloss.NewStyle().Bold(true).Foreground(styles.DefaultTheme.SidebarDateTime).Render(when)
 	styledDescription := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("4")).Render("Description:\n------------")
-	location := lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Render(fmt.Sprintf("%s, %s", event.Location.VenueName, event.Location.VenueAddress))
+	location := s.highlight(fmt.Sprintf("%s, %s", event.Location.VenueName, event.Location.VenueAddress), lipgloss.NewStyle().Foreground(lipgloss.Color("6")))
 
 	sidebarText := fmt.Sprintf(
-		"%s\n\n🔗 %s\n\n📍 %s\n\n📅 %s\n\n%s\n%s",
-		title, url, location, date, styledDescription, description,
+		"%s\n\n🔗 %s\n\n📍 %s\n\n📅 %s\n\n%s%s\n%s",
+		title, url, location, date, eventDetails(event), styledDescription, description,
 	)
 	// Split into lines
 	lines := strings.Split(sidebarText, "\n")
@@ -77,6 +98,75 @@ func (s *Sidebar) UpdateSidebarContent(event types.Event, height int) {
 
 }
 
+func (s *Sidebar) highlight(text string, style lipgloss.Style) string {
+	if s.Match == nil {
+		return style.Render(text)
+	}
+	return highlight(text, s.Match(text), style)
+}
+
+// eventEnd returns when the event ends, from EndDateTime or DurationMinutes
+func eventEnd(event types.Event, start time.Time, zone *time.Location) (time.Time, bool) {
+	if event.EndDateTime != "" {
+		if end, _, err := api.ParseDateTimeIn(event.EndDateTime, zone); err == nil {
+			return end, true
+		}
+	}
+	if event.DurationMinutes > 0 && !start.IsZero() {
+		return start.Add(time.Duration(event.DurationMinutes) * time.Minute), true
+	}
+	return time.Time{}, false
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// eventDetails renders the optional fields, one line each, skipping unknown ones
+func eventDetails(event types.Event) string {
+	var lines []string
+	if event.Organizer != nil && event.Organizer.Name != "" {
+		organizer := event.Organizer.Name
+		if event.Organizer.Url != "" {
+			organizer = fmt.Sprintf("%s (%s)", organizer, event.Organizer.Url)
+		}
+		lines = append(lines, "👥 "+organizer)
+	}
+	if len(event.Tags) > 0 {
+		tags := make([]string, len(event.Tags))
+		for i, t := range event.Tags {
+			tags[i] = "#" + t
+		}
+		lines = append(lines, "🏷  "+strings.Join(tags, " "))
+	}
+	if event.Price != nil {
+		lines = append(lines, "🎟  "+event.Price.String())
+	}
+	if event.Capacity > 0 || event.WaitlistCount > 0 {
+		attendance := fmt.Sprintf("%d going", event.RsvpsCount)
+		if event.Capacity > 0 {
+			attendance = fmt.Sprintf("%d / %d going", event.RsvpsCount, event.Capacity)
+		}
+		if event.WaitlistCount > 0 {
+			attendance = fmt.Sprintf("%s, %d on waitlist", attendance, event.WaitlistCount)
+		}
+		lines = append(lines, "🙋 "+attendance)
+	}
+	if event.OnlineUrl != "" {
+		online := lipgloss.NewStyle().Foreground(styles.DefaultTheme.SidebarUrl).Render(event.OnlineUrl)
+		lines = append(lines, "💻 "+online)
+	}
+	if event.ImageUrl != "" {
+		lines = append(lines, "🖼  "+event.ImageUrl)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n\n"
+}
+
 func (s *Sidebar) Update(msg tea.Msg) (Sidebar, tea.Cmd) {
 	var cmd tea.Cmd
 	s.Viewport, cmd = s.Viewport.Update(msg)
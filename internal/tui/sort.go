@@ -0,0 +1,234 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"mcli/internal/api"
+	"mcli/internal/columns"
+	"mcli/internal/types"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// Sort keys of the event table
+const (
+	SortDate       = "date"
+	SortTitle      = "title"
+	SortRsvps      = "rsvps"
+	SortVenue      = "venue"
+	SortSource     = "source"
+	SortBookmarked = "bookmarked" // bookmarked events first
+	SortUnread     = "unread"     // unread events first
+)
+
+// SortKeys is the order the sort key cycles through
+var SortKeys = []string{SortDate, SortTitle, SortRsvps, SortVenue, SortSource, SortBookmarked, SortUnread}
+
+// Sort orders the event table; ties keep date order
+type Sort struct {
+	Key  string
+	Desc bool
+}
+
+// ParseSort reads a key optionally followed by asc or desc, e.g. "rsvps desc".
+// The empty string sorts by date.
+func ParseSort(s string) (Sort, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) == 0 {
+		return Sort{Key: SortDate}, nil
+	}
+	key := fields[0]
+	if key == "rsvp" || key == "going" {
+		key = SortRsvps
+	}
+	if !slices.Contains(SortKeys, key) {
+		return Sort{}, fmt.Errorf("unknown sort %q, use %s", fields[0], strings.Join(SortKeys, ", "))
+	}
+	srt := Sort{Key: key}
+	if len(fields) > 1 {
+		switch fields[1] {
+		case "asc":
+		case "desc":
+			srt.Desc = true
+		default:
+			return Sort{}, fmt.Errorf("unknown order %q, use asc or desc", fields[1])
+		}
+	}
+	if len(fields) > 2 {
+		return Sort{}, fmt.Errorf("too many words in %q, use <key> [asc|desc]", s)
+	}
+	return srt, nil
+}
+
+// String is the form ParseSort reads back, used to save the sort
+func (s Sort) String() string {
+	key := s.Key
+	if key == "" {
+		key = SortDate
+	}
+	if s.Desc {
+		return key + " desc"
+	}
+	return key
+}
+
+// Next returns the key after s in SortKeys, ascending
+func (s Sort) Next() Sort {
+	i := slices.Index(SortKeys, s.Key)
+	return Sort{Key: SortKeys[(i+1)%len(SortKeys)]}
+}
+
+// IsDefault reports whether s is the date order events are loaded in
+func (s Sort) IsDefault() bool {
+	return (s.Key == "" || s.Key == SortDate) && !s.Desc
+}
+
+// Label describes the sort, e.g. "rsvps ↓"
+func (s Sort) Label() string {
+	key := s.Key
+	if key == "" {
+		key = SortDate
+	}
+	return key + " " + s.arrow()
+}
+
+func (s Sort) arrow() string {
+	if s.Desc {
+		return "↓"
+	}
+	return "↑"
+}
+
+// Apply returns events, which are sorted by date, in s's order. Events
+// without a valid date stay last when sorting by date either way.
+func (s Sort) Apply(events []types.Event, rc RowContext) []types.Event {
+	if s.IsDefault() {
+		return events
+	}
+	compare := s.compareFn(rc)
+	if compare == nil {
+		return events
+	}
+	sorted := slices.Clone(events)
+	slices.SortStableFunc(sorted, func(a, b types.Event) int {
+		if s.Desc {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+	if s.Key == SortDate {
+		// undated events were put first by the reversal
+		undated := 0
+		for undated < len(sorted) && !hasDate(sorted[undated], rc.Zone) {
+			undated++
+		}
+		sorted = append(sorted[undated:], sorted[:undated]...)
+	}
+	return sorted
+}
+
+func (s Sort) compareFn(rc RowContext) func(a, b types.Event) int {
+	switch s.Key {
+	case SortDate:
+		return func(a, b types.Event) int {
+			at, _, errA := api.ParseDateTimeIn(a.DateTime, rc.Zone)
+			bt, _, errB := api.ParseDateTimeIn(b.DateTime, rc.Zone)
+			if errA != nil || errB != nil {
+				return cmp.Compare(boolRank(errA == nil), boolRank(errB == nil))
+			}
+			return at.Compare(bt)
+		}
+	case SortTitle:
+		return func(a, b types.Event) int {
+			return cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		}
+	case SortRsvps:
+		return func(a, b types.Event) int {
+			return cmp.Compare(a.RsvpsCount, b.RsvpsCount)
+		}
+	case SortVenue:
+		return func(a, b types.Event) int {
+			va, vb := venueKey(a), venueKey(b)
+			if va == "" || vb == "" {
+				return cmp.Compare(boolRank(va != ""), boolRank(vb != ""))
+			}
+			return cmp.Compare(va, vb)
+		}
+	case SortSource:
+		return func(a, b types.Event) int {
+			return cmp.Compare(strings.ToLower(a.Source), strings.ToLower(b.Source))
+		}
+	case SortBookmarked:
+		if rc.IsBookmarked == nil {
+			return nil
+		}
+		return func(a, b types.Event) int {
+			return cmp.Compare(boolRank(rc.IsBookmarked(a.ID)), boolRank(rc.IsBookmarked(b.ID)))
+		}
+	case SortUnread:
+		if rc.IsRead == nil {
+			return nil
+		}
+		return func(a, b types.Event) int {
+			return cmp.Compare(boolRank(!rc.IsRead(a.ID)), boolRank(!rc.IsRead(b.ID)))
+		}
+	}
+	return nil
+}
+
+// boolRank sorts true before false
+func boolRank(b bool) int {
+	if b {
+		return 0
+	}
+	return 1
+}
+
+func hasDate(e types.Event, zone *time.Location) bool {
+	_, _, err := api.ParseDateTimeIn(e.DateTime, zone)
+	return err == nil
+}
+
+// venueKey sorts by venue name, then address
+func venueKey(e types.Event) string {
+	return strings.ToLower(strings.TrimSpace(e.VenueName + " " + e.VenueAddress))
+}
+
+// sortColumns are the columns showing what each sort key orders by
+var sortColumns = map[string][]string{
+	SortDate:       {columns.In, columns.Date},
+	SortTitle:      {columns.Event},
+	SortRsvps:      {columns.Rsvps},
+	SortVenue:      {columns.Venue, columns.Location},
+	SortSource:     {columns.Source, columns.Icon},
+	SortBookmarked: {columns.Mark},
+	SortUnread:     {columns.Icon},
+}
+
+// markSortColumn adds the sort arrow to the header of the first shown column
+// s orders by, or to the Event header when none is shown
+func markSortColumn(cols []table.Column, layout []columns.Column, s Sort) []table.Column {
+	if s.Key == "" {
+		s.Key = SortDate
+	}
+	event := -1
+	for i, c := range layout {
+		if i >= len(cols) || cols[i].Width == 0 {
+			continue
+		}
+		if slices.Contains(sortColumns[s.Key], c.Name) {
+			cols[i].Title += " " + s.arrow()
+			return cols
+		}
+		if c.Name == columns.Event {
+			event = i
+		}
+	}
+	if event >= 0 {
+		cols[event].Title = fmt.Sprintf("%s (%s)", cols[event].Title, s.Label())
+	}
+	return cols
+}
@@ -1,7 +1,10 @@
 package tui
 
 import (
+	"fmt"
 	"mcli/internal/tui/styles"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -10,6 +13,9 @@ import (
 type StatusBar struct {
 	helpText     string // Text to display on the left (e.g., help menu)
 	FilteredText string // Text to display on the right (e.g., current filter)
+	NetStatus    string // Network state shown left of the filter (retries, breaker)
+	CacheStatus  string // Age of cached events, when they are what's shown
+	Window       string // Time window other than the default upcoming one
 	Width        int    // Width of the status bar, typically the terminal width
 }
 
@@ -22,11 +28,40 @@ func NewStatusBar(helpText, filteredText string, width int) StatusBar {
 	}
 }
 
+// CacheStatus describes the age of cached events; empty when showing fresh data online
+func CacheStatus(fetchedAt time.Time, offline, fromCache bool) string {
+	if !offline && !fromCache {
+		return ""
+	}
+	age := "never"
+	if !fetchedAt.IsZero() {
+		age = formatAge(time.Since(fetchedAt)) + " ago"
+	}
+	if offline {
+		return "offline · cached " + age
+	}
+	return "cached " + age
+}
+
+// formatAge renders a duration in its largest unit, e.g. "5m", "3h", "2d"
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
 // View renders the status bar as a single line with help text on the left and filter text on the right.
 func (s StatusBar) View() string {
 	// Prepare left and right content
 	left := s.helpText
-	right := s.FilteredText
+	right := strings.TrimSpace(strings.Join([]string{s.NetStatus, s.CacheStatus, s.Window, s.FilteredText}, " "))
 
 	// Truncate text if it exceeds half the width to prevent overlap
 	if lipgloss.Width(left) > s.Width/2 {
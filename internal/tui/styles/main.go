@@ -6,7 +6,9 @@ import (
 )
 
 var (
-	BaseStyle = lipgloss.NewStyle().BorderStyle(lipgloss.HiddenBorder()).BorderForeground(lipgloss.Color("240"))
+	BaseStyle  = lipgloss.NewStyle().BorderStyle(lipgloss.HiddenBorder()).BorderForeground(lipgloss.Color("240"))
+	PastStyle  = lipgloss.NewStyle().Faint(true)                                // events that already started
+	MatchStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("3")) // characters matching the filter
 )
 
 type Theme struct {
@@ -45,6 +47,39 @@ var DefaultTheme = &Theme{
 	StatusForeground: lipgloss.AdaptiveColor{Light: "#3C3C3c", Dark: "240"},
 }
 
+var LightTheme = &Theme{
+
+	TableHeader:                lipgloss.AdaptiveColor{Light: "22", Dark: "22"},
+	TableRowSelectedForeground: lipgloss.AdaptiveColor{Light: "255", Dark: "255"},
+	TableRowSelectedBackground: lipgloss.AdaptiveColor{Light: "25", Dark: "25"},
+	FaintBorder:                lipgloss.AdaptiveColor{Light: "250", Dark: "250"},
+	TableRows:                  lipgloss.AdaptiveColor{Light: "236", Dark: "236"},
+
+	SidebarTitle:    lipgloss.AdaptiveColor{Light: "22", Dark: "22"},
+	SidebarUrl:      lipgloss.AdaptiveColor{Light: "94", Dark: "94"},
+	SidebarLocation: lipgloss.AdaptiveColor{Light: "25", Dark: "25"},
+	SidebarDateTime: lipgloss.AdaptiveColor{Light: "90", Dark: "90"},
+
+	StatusBackground: lipgloss.AdaptiveColor{Light: "252", Dark: "252"},
+	StatusForeground: lipgloss.AdaptiveColor{Light: "236", Dark: "236"},
+}
+
+// Themes maps theme names (as used in the config file) to a copy of each theme
+var Themes = map[string]Theme{
+	"default": *DefaultTheme,
+	"light":   *LightTheme,
+}
+
+// UseTheme makes the named theme the active one, returns false if unknown
+func UseTheme(name string) bool {
+	t, ok := Themes[name]
+	if !ok {
+		return false
+	}
+	*DefaultTheme = t
+	return true
+}
+
 func GetTableStyles() table.Styles {
 	s := table.DefaultStyles()
 	s.Header = s.Header.
@@ -2,79 +2,48 @@ package tui
 
 import (
 	"mcli/internal/api"
+	"mcli/internal/columns"
 	"mcli/internal/tui/styles"
 	"mcli/internal/types"
 	"mcli/internal/utils"
+	"slices"
+	"time"
 
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/mattn/go-runewidth"
 )
 
 type Table struct {
 	table.Model
+	showDistance bool
+	sort         Sort             // shown in the header, applied by the caller
+	layout       []columns.Column // configured columns, some may be hidden by AdjustColumns
 }
 
 // EventMarkerFn checks if an event has a particular marker (bookmark, read, etc.)
 type EventMarkerFn func(types.EventId) bool
 
-func getTableColumns(width int, isSidebarVisible bool) []table.Column {
+// DistanceFn returns the rendered distance to an event, or "" when unknown
+type DistanceFn func(types.Event) string
 
-	iconWidth := 2
-	markWidth := 2
-	dateWidth := 8 // 8 chars enough
-	locationWidth := int(float64(width) * 0.25)
-
-	// hide location if sidebar is shown
-	if isSidebarVisible {
-		locationWidth = 0
-	}
-	remaining := width - locationWidth - dateWidth - iconWidth - markWidth
-	eventWidth := int(float64(remaining) * 0.95)
-	utils.Logger.Info("getTableColumns", "width", width)
-	utils.Logger.Info("getTableColumns", "locationWidth", locationWidth)
-	utils.Logger.Info("getTableColumns", "dateWidth", dateWidth)
-	utils.Logger.Info("getTableColumns", "eventWidth", eventWidth)
-
-	return []table.Column{
-		{Title: "🚀", Width: iconWidth},
-		{Title: "Event", Width: eventWidth},
-		{Title: "☆", Width: markWidth},
-		{Title: "Location", Width: locationWidth},
-		{Title: "In", Width: dateWidth},
-	}
+// RowContext is the per-user state rows are rendered with; nil fields are skipped
+type RowContext struct {
+	IsBookmarked EventMarkerFn
+	IsRead       EventMarkerFn
+	Distance     DistanceFn
+	Match        MatchFn        // highlights the title characters the filter matched
+	Zone         *time.Location // user's time zone, nil for the local one
 }
 
-func CreateTableRows(events []types.Event, isBookmarked, isRead EventMarkerFn) []table.Row {
+// CreateTableRows renders a row per event with a cell per column of layout
+func CreateTableRows(events []types.Event, layout []columns.Column, rc RowContext) []table.Row {
 	var rows []table.Row
 	for _, event := range events {
-		sourceIcon := "?"
-		switch event.Source {
-		case "luma":
-			sourceIcon = "✦︎"
-		default:
-			sourceIcon = "☘️"
-		}
-
-		title := event.Title
-		_, _, dateTime, _ := api.ParseAndCompareDateTime(event.DateTime)
-		location := event.Location.VenueAddress
-
-		// Show · instead of source icon for read events
-		if isRead != nil && isRead(event.ID) {
-			sourceIcon = "·"
-		}
-
-		mark := " "
-		if isBookmarked != nil && isBookmarked(event.ID) {
-			mark = "★"
+		row := make(table.Row, len(layout))
+		for i, c := range layout {
+			row[i] = columnDefs[c.Name].cell(event, rc)
 		}
-
-		rows = append(rows, table.Row{
-			sourceIcon,
-			title,
-			mark,
-			location,
-			dateTime,
-		})
+		rows = append(rows, row)
 	}
 	return rows
 }
@@ -82,25 +51,102 @@ func CreateTableRows(events []types.Event, isBookmarked, isRead EventMarkerFn) [
 // Initialize new table
 func NewTable(events []types.Event) Table {
 	width := 20 // initial width size of table, will be adjusted dynamically
-	showTitleOnly := false
+	layout, _ := columns.Parse(columns.Default)
 	t := table.New(
-		table.WithColumns(getTableColumns(width, showTitleOnly)),
-		table.WithRows(CreateTableRows(events, nil, nil)),
+		table.WithColumns(layoutColumns(layout, width, false)),
+		table.WithRows(CreateTableRows(events, layout, RowContext{})),
 		table.WithFocused(true),
 	)
 	t.SetStyles(styles.GetTableStyles())
-	return Table{t}
+	return Table{Model: t, layout: layout}
+}
+
+// SetLayout sets the columns shown, sized by AdjustColumns
+func (t *Table) SetLayout(layout []columns.Column) {
+	// rows must match the columns, the caller sets new ones
+	if len(layout) != len(t.layout) {
+		t.SetRows(nil)
+	}
+	t.layout = layout
+	t.AdjustColumns()
 }
 
-// dynamically adjust the column width
-func (t *Table) AdjustColumns(termWidth int, isSidebarVisible bool) {
+// Layout returns the columns shown
+func (t *Table) Layout() []columns.Column {
+	return t.layout
+}
+
+// SetShowDistance shows or hides the distance column, applied by AdjustColumns
+func (t *Table) SetShowDistance(show bool) {
+	t.showDistance = show
+}
 
-	if termWidth < 70 {
-		isSidebarVisible = true
+// SetSort sets the order marked in the header, applied by AdjustColumns
+func (t *Table) SetSort(s Sort) {
+	t.sort = s
+}
+
+// SetEvents fills the table with events, dimming those that already started
+// and highlighting what the filter matched in titles
+func (t *Table) SetEvents(events []types.Event, rc RowContext) {
+	rows := CreateTableRows(events, t.layout, rc)
+	now := time.Now()
+	cols := t.Columns()
+	title := slices.IndexFunc(t.layout, func(c columns.Column) bool { return c.Name == columns.Event })
+	for i, e := range events {
+		if isPast(e, now, rc.Zone) {
+			rows[i] = dimRow(rows[i], cols)
+		}
+		if rc.Match == nil || title < 0 || title >= len(cols) {
+			continue
+		}
+		if positions := rc.Match(e.Title); len(positions) > 0 {
+			rows[i][title] = highlightCell(e.Title, positions, cols[title].Width)
+		}
 	}
+	t.SetRows(rows)
+}
+
+// SetPlaceholders keeps n blank rows while another view shows the events,
+// so the cursor the views share stays in range
+func (t *Table) SetPlaceholders(n int) {
+	t.SetRows(make([]table.Row, n))
+}
+
+// isPast reports whether e started before now; all-day events once their day is over
+func isPast(e types.Event, now time.Time, zone *time.Location) bool {
+	start, allDay, err := api.ParseDateTimeIn(e.DateTime, zone)
+	if err != nil {
+		return false
+	}
+	if allDay {
+		return !start.Add(24 * time.Hour).After(now)
+	}
+	return start.Before(now)
+}
+
+// dimRow renders the wide text cells of row faint. The table truncates cells
+// by rune width and counts escape codes as text, so cells are shortened by
+// the style's overhead first to keep the codes intact.
+func dimRow(row table.Row, cols []table.Column) table.Row {
+	overhead := runewidth.StringWidth(styles.PastStyle.Render("x")) - 1
+	dimmed := make(table.Row, len(row))
+	for i, cell := range row {
+		dimmed[i] = cell
+		if i >= len(cols) || cell == "" || cols[i].Width <= overhead+4 {
+			continue
+		}
+		cell = runewidth.Truncate(cell, cols[i].Width-overhead, "…")
+		dimmed[i] = styles.PastStyle.Render(cell)
+	}
+	return dimmed
+}
 
+// AdjustColumns sizes the layout to the table width, dropping low
+// priority columns when it is too narrow
+func (t *Table) AdjustColumns() {
 	utils.Logger.Debug("AdjustColumns", "tableWidth", t.Width())
-	columns := getTableColumns(t.Width(), isSidebarVisible)
+	columns := markSortColumn(layoutColumns(t.layout, t.Width(), t.showDistance), t.layout, t.sort)
 
 	utils.Logger.Debug("AdjustColumns", "columns", columns)
 	t.SetColumns(columns)
@@ -0,0 +1,171 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"mcli/internal/utils"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SchemaVersion is the newest event schema this client understands.
+// Version 1 backends only send the flat fields; version 2 adds EventDetails.
+// Newer versions are decoded best-effort, ignoring unknown fields.
+const SchemaVersion = 2
+
+// warns once about payloads newer than SchemaVersion, not for every event
+var newerSchema sync.Once
+
+// eventAlias has Event's fields without its UnmarshalJSON method
+type eventAlias Event
+
+// eventWire shadows the fields whose shape differs between backends
+type eventWire struct {
+	eventAlias
+	SchemaVersion int             `json:"schemaVersion"`
+	Organizer     json.RawMessage `json:"organizer"` // {"name","url"} or a plain name
+	GroupName     string          `json:"groupName"` // v1 spelling of organizer
+	Tags          json.RawMessage `json:"tags"`      // list or comma separated string
+	Topics        json.RawMessage `json:"topics"`    // v1 spelling of tags
+	Price         json.RawMessage `json:"price"`     // {"amount","currency"}, number or string
+	Currency      string          `json:"currency"`
+	EndTime       string          `json:"endTime"` // v1 spelling of endDateTime
+	Lat           float64         `json:"lat"`     // short spellings of latitude/longitude
+	Lng           float64         `json:"lng"`
+}
+
+// UnmarshalJSON decodes events from any backend version. An organizer, tags
+// or price of an unexpected shape is logged and left empty rather than
+// failing the whole event list.
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var w eventWire
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	*e = Event(w.eventAlias)
+
+	if w.SchemaVersion > SchemaVersion {
+		newerSchema.Do(func() {
+			warn("event schema is newer than this client, some fields may be missing",
+				"schemaVersion", w.SchemaVersion, "supported", SchemaVersion)
+		})
+	}
+
+	if e.EndDateTime == "" {
+		e.EndDateTime = w.EndTime
+	}
+	if e.Latitude == 0 && e.Longitude == 0 {
+		e.Latitude, e.Longitude = w.Lat, w.Lng
+	}
+
+	organizer, err := decodeOrganizer(w.Organizer)
+	if err != nil {
+		warn("ignoring event organizer", "id", e.ID, "organizer", string(w.Organizer), "err", err)
+	}
+	if organizer == nil && w.GroupName != "" {
+		organizer = &Organizer{Name: w.GroupName}
+	}
+	e.Organizer = organizer
+
+	raw := w.Tags
+	if len(raw) == 0 {
+		raw = w.Topics
+	}
+	if e.Tags, err = decodeTags(raw); err != nil {
+		warn("ignoring event tags", "id", e.ID, "tags", string(raw), "err", err)
+	}
+
+	if e.Price, err = decodePrice(w.Price, w.Currency); err != nil {
+		warn("ignoring event price", "id", e.ID, "price", string(w.Price), "err", err)
+	}
+	return nil
+}
+
+// warn logs a decoding problem; the logger isn't set up outside the app
+func warn(msg string, args ...any) {
+	if utils.Logger != nil {
+		utils.Logger.Warn(msg, args...)
+	}
+}
+
+func isNull(raw json.RawMessage) bool {
+	s := strings.TrimSpace(string(raw))
+	return s == "" || s == "null"
+}
+
+func decodeOrganizer(raw json.RawMessage) (*Organizer, error) {
+	if isNull(raw) {
+		return nil, nil
+	}
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		if name == "" {
+			return nil, nil
+		}
+		return &Organizer{Name: name}, nil
+	}
+	var o Organizer
+	if err := json.Unmarshal(raw, &o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func decodeTags(raw json.RawMessage) ([]string, error) {
+	if isNull(raw) {
+		return nil, nil
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list, nil
+	}
+	var joined string
+	if err := json.Unmarshal(raw, &joined); err != nil {
+		return nil, err
+	}
+	for _, t := range strings.Split(joined, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			list = append(list, t)
+		}
+	}
+	return list, nil
+}
+
+func decodePrice(raw json.RawMessage, currency string) (*Price, error) {
+	if isNull(raw) {
+		return nil, nil
+	}
+	var amount float64
+	if err := json.Unmarshal(raw, &amount); err == nil {
+		return &Price{Amount: amount, Currency: currency}, nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return nil, nil
+		}
+		if strings.EqualFold(text, "free") {
+			return &Price{Currency: currency}, nil
+		}
+		// "12.50" or "NPR 500"
+		fields := strings.Fields(text)
+		if len(fields) == 2 {
+			currency, text = fields[0], fields[1]
+		}
+		amount, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid price %q", text)
+		}
+		return &Price{Amount: amount, Currency: currency}, nil
+	}
+	var p Price
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, err
+	}
+	if p.Currency == "" {
+		p.Currency = currency
+	}
+	return &p, nil
+}
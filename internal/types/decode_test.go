@@ -0,0 +1,89 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want func(e *Event)
+	}{
+		{
+			name: "v2",
+			json: `{"id":"1","schemaVersion":2,"organizer":{"name":"Rust Nepal","url":"https://x"},"tags":["rust"],"price":{"amount":500,"currency":"NPR"},"endDateTime":"2026-11-01T20:00:00Z"}`,
+			want: func(e *Event) {
+				e.Organizer = &Organizer{Name: "Rust Nepal", Url: "https://x"}
+				e.Tags = []string{"rust"}
+				e.Price = &Price{Amount: 500, Currency: "NPR"}
+				e.EndDateTime = "2026-11-01T20:00:00Z"
+			},
+		},
+		{
+			name: "v1 spellings",
+			json: `{"id":"1","groupName":"Rust Nepal","topics":"rust, systems","price":"NPR 500","endTime":"2026-11-01T20:00:00Z","lat":27.7,"lng":85.3}`,
+			want: func(e *Event) {
+				e.Organizer = &Organizer{Name: "Rust Nepal"}
+				e.Tags = []string{"rust", "systems"}
+				e.Price = &Price{Amount: 500, Currency: "NPR"}
+				e.EndDateTime = "2026-11-01T20:00:00Z"
+				e.Latitude, e.Longitude = 27.7, 85.3
+			},
+		},
+		{
+			name: "free",
+			json: `{"id":"1","price":"Free","currency":"NPR"}`,
+			want: func(e *Event) { e.Price = &Price{Currency: "NPR"} },
+		},
+		{
+			name: "bad price",
+			json: `{"id":"1","title":"Kept","price":"ask at the door"}`,
+			want: func(e *Event) { e.Title = "Kept" },
+		},
+		{
+			name: "bad organizer and tags",
+			json: `{"id":"1","title":"Kept","organizer":42,"tags":{"rust":true}}`,
+			want: func(e *Event) { e.Title = "Kept" },
+		},
+		{
+			name: "newer schema",
+			json: `{"id":"1","schemaVersion":99,"title":"Kept","futureField":true}`,
+			want: func(e *Event) { e.Title = "Kept" },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Event
+			if err := json.Unmarshal([]byte(tt.json), &got); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			want := Event{ID: "1"}
+			tt.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+// one bad event must not fail the list
+func TestUnmarshalEventsLenient(t *testing.T) {
+	var events Events
+	data := `[{"id":"1","price":{"amount":"lots"}},{"id":"2","price":5}]`
+	if err := json.Unmarshal([]byte(data), &events); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(events) != 2 || events[0].Price != nil || events[1].Price == nil || events[1].Price.Amount != 5 {
+		t.Errorf("got %+v", events)
+	}
+}
+
+func TestUnmarshalEventInvalidJSON(t *testing.T) {
+	var e Event
+	if err := json.Unmarshal([]byte(`{"id":`), &e); err == nil {
+		t.Error("Unmarshal of truncated JSON succeeded")
+	}
+}
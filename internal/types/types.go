@@ -1,10 +1,18 @@
 package types
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 type EventId string
 
 type Location struct {
-	VenueAddress string `json:"venueAddress"`
-	VenueName    string `json:"venueName"`
+	VenueAddress string  `json:"venueAddress"`
+	VenueName    string  `json:"venueName"`
+	Latitude     float64 `json:"latitude,omitempty"` // 0,0 when the backend didn't geocode the venue
+	Longitude    float64 `json:"longitude,omitempty"`
 }
 type Event struct {
 	ID          EventId `json:"id"`
@@ -15,6 +23,7 @@ type Event struct {
 	Source      string  `json:"source"`
 	EventMeta
 	Location
+	EventDetails
 }
 
 type EventMeta struct {
@@ -23,6 +32,66 @@ type EventMeta struct {
 	RsvpsCount int    `json:"rsvpCount"`
 }
 
+// EventDetails are optional fields sent by newer backends (schema version 2+)
+type EventDetails struct {
+	EndDateTime     string     `json:"endDateTime,omitempty"`
+	DurationMinutes int        `json:"durationMinutes,omitempty"` // used when EndDateTime is missing
+	Organizer       *Organizer `json:"organizer,omitempty"`
+	Tags            []string   `json:"tags,omitempty"`
+	Price           *Price     `json:"price,omitempty"` // nil when unknown
+	Capacity        int        `json:"capacity,omitempty"`
+	WaitlistCount   int        `json:"waitlistCount,omitempty"`
+	OnlineUrl       string     `json:"onlineUrl,omitempty"`
+	ImageUrl        string     `json:"imageUrl,omitempty"`
+}
+
+// Organizer is the person or group hosting an event
+type Organizer struct {
+	Name string `json:"name"`
+	Url  string `json:"url,omitempty"`
+}
+
+// Price of a ticket; an Amount of 0 means free
+type Price struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency,omitempty"`
+}
+
+// String formats the price as "Free", "NPR 500" or "12.50"
+func (p Price) String() string {
+	if p.Amount == 0 {
+		return "Free"
+	}
+	amount := strconv.FormatFloat(p.Amount, 'f', -1, 64)
+	if p.Amount != float64(int64(p.Amount)) {
+		amount = fmt.Sprintf("%.2f", p.Amount)
+	}
+	if p.Currency == "" {
+		return amount
+	}
+	return p.Currency + " " + amount
+}
+
+// HasTag reports whether the event is tagged with tag (case-insensitive)
+func (e Event) HasTag(tag string) bool {
+	for _, t := range e.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFree reports whether the event is known to be free
+func (e Event) IsFree() bool {
+	return e.Price != nil && e.Price.Amount == 0
+}
+
+// IsOnline reports whether the event can be joined online
+func (e Event) IsOnline() bool {
+	return e.OnlineUrl != "" || e.Type == "online"
+}
+
 type Events []Event
 
 type ErrMsg struct{ Err error }
@@ -31,3 +100,11 @@ type EventsMsg struct {
 	Events []Event
 	Err    error
 }
+
+// SourceSpec configures one event source provider
+type SourceSpec struct {
+	Name     string `toml:"name"`
+	Kind     string `toml:"kind"`     // "mcli.d", "file" or "ical"
+	Location string `toml:"location"` // file path or URL, unused for mcli.d
+	Enabled  bool   `toml:"enabled"`
+}
@@ -1,22 +1,40 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"mcli/internal/api"
+	"mcli/internal/calfeed"
+	"mcli/internal/config"
+	"mcli/internal/eventpool"
 	"mcli/internal/profile"
+	"mcli/internal/tui/styles"
 	"mcli/internal/utils"
+	"net"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/ssh"
 	"github.com/charmbracelet/wish"
 	"github.com/charmbracelet/wish/bubbletea"
+	"github.com/muesli/termenv"
 	gossh "golang.org/x/crypto/ssh"
 )
 
-// Global store shared across SSH sessions
-var store *profile.Store
+// Global store, config, API client and event pool shared across SSH sessions
+var (
+	store  *profile.Store
+	cfg    *config.Config
+	client *api.Client
+	pool   *eventpool.Pool // nil outside wish mode or when offline
+
+	calendarURL string // public base of the calendar feeds, empty when they aren't served
+)
 
 // teaHandler creates a Bubble Tea program for the Wish server.
 func teaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
@@ -26,7 +44,15 @@ func teaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
 	}
 	utils.Logger.Info("SSH session started", "user", s.User(), "userID", userID)
 
-	m := NewModel(userID, store)
+	// requests are cancelled when the SSH session ends
+	m := NewModel(s.Context(), userID, store, client, pool, cfg)
+	m.calendarURL = calendarURL
+	// sent by clients configured with SendEnv TZ
+	for _, kv := range s.Environ() {
+		if tz, ok := strings.CutPrefix(kv, "TZ="); ok {
+			m.UseSessionTimezone(tz)
+		}
+	}
 	opts := []tea.ProgramOption{
 		tea.WithInput(s),
 		tea.WithOutput(s),
@@ -34,17 +60,51 @@ func teaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
 	return m, opts
 }
 
+// programHandler creates the session's program and subscribes it to the event pool
+func programHandler(s ssh.Session) *tea.Program {
+	m, opts := teaHandler(s)
+	p := tea.NewProgram(m, append(opts, bubbletea.MakeOptions(s)...)...)
+	if pool != nil {
+		unsubscribe := pool.Subscribe(p)
+		go func() {
+			<-s.Context().Done()
+			unsubscribe()
+		}()
+	}
+	return p
+}
+
 // runWishServer starts a Charm Wish SSH server to serve the Bubble Tea app.
-func runWishServer(host, port string) error {
+func runWishServer(cfg *config.Config, host, port string) error {
+	// a busy port is reported before anything starts
+	if cfg.CalendarAddr != "" {
+		ln, err := net.Listen("tcp", cfg.CalendarAddr)
+		if err != nil {
+			return fmt.Errorf("could not serve calendar feeds: %w", err)
+		}
+		go serveCalendars(ln)
+		// only a loopback address may go without a public URL
+		calendarURL = cfg.CalendarURL
+		if calendarURL == "" {
+			calendarURL = "http://" + ln.Addr().String()
+		}
+	}
+
+	// one refresh loop serves every session
+	if !cfg.Offline {
+		pool = eventpool.New(client, store, cfg.RefreshInterval.Duration)
+		go pool.Run(context.Background())
+	}
+
 	s, err := wish.NewServer(
 		wish.WithAddress(fmt.Sprintf("%s:%s", host, port)),
-		wish.WithHostKeyPath(".ssh/events_app_ed25519"),
+		wish.WithHostKeyPath(cfg.HostKeyPath),
 		wish.WithPublicKeyAuth(func(ctx ssh.Context, key ssh.PublicKey) bool {
 			// Accept all public keys — identity is used for profiles, not access control
 			return true
 		}),
 		wish.WithMiddleware(
-			bubbletea.Middleware(teaHandler),
+			bubbletea.MiddlewareWithProgramHandler(programHandler, termenv.Ascii),
 		),
 	)
 	if err != nil {
@@ -56,14 +116,56 @@ func runWishServer(host, port string) error {
 	return s.ListenAndServe()
 }
 
+// serveCalendars serves the bookmark feeds created with :calendar-link on ln
+func serveCalendars(ln net.Listener) {
+	mux := http.NewServeMux()
+	mux.Handle(calfeed.Path, calfeed.New(store))
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("Serving calendar feeds on %s", ln.Addr())
+	if err := srv.Serve(ln); err != nil {
+		log.Printf("Calendar feeds stopped: %v", err)
+	}
+}
+
+// newAPIClient builds the API client; in wish mode its breaker is shared by every session
+func newAPIClient(cfg *config.Config) *api.Client {
+	opts := []api.Option{
+		api.WithTimeout(cfg.HTTPTimeout.Duration),
+		api.WithRetry(api.RetryPolicy{
+			MaxAttempts: cfg.RetryAttempts,
+			BaseDelay:   cfg.RetryBackoff.Duration,
+			MaxDelay:    api.DefaultRetryPolicy.MaxDelay,
+		}),
+	}
+	if cfg.BreakerThreshold > 0 {
+		opts = append(opts, api.WithBreaker(api.NewBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown.Duration)))
+	}
+	return api.NewClient(cfg.APIBaseURL, opts...)
+}
+
 func main() {
 
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			utils.InitLogger(false)
+			if err := run(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	var debug bool
 	flag.BoolVar(&debug, "debug", false, "Enable debug logging to debug.log")
 	// Define command-line flags
 	wishMode := flag.Bool("wish", false, "Run as a Charm Wish SSH server instead of CLI")
 	host := flag.String("host", "localhost", "Host address for the Wish server")
 	port := flag.String("port", "2222", "Port for the Wish server")
+	configFlags := config.BindFlags(flag.CommandLine)
 
 	flag.Parse()
 	// Initialize the global logger
@@ -72,9 +174,19 @@ func main() {
 	// Log a startup message
 	utils.Logger.Info("Program started")
 
-	// Open the profile store (SQLite)
+	// Merge defaults, config file, environment and flags
 	var err error
-	store, err = profile.OpenStore()
+	cfg, err = config.Load(configFlags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	utils.Logger.Info("config loaded", "file", cfg.File, "api", cfg.APIBaseURL)
+	client = newAPIClient(cfg)
+	styles.UseTheme(cfg.Theme)
+
+	// Open the profile store (SQLite)
+	store, err = profile.OpenStore(cfg.DBPath)
 	if err != nil {
 		log.Fatalf("Failed to open profile store: %v", err)
 	}
@@ -82,13 +194,13 @@ func main() {
 
 	if *wishMode {
 		// Run as Wish SSH server
-		if err := runWishServer(*host, *port); err != nil {
+		if err := runWishServer(cfg, *host, *port); err != nil {
 			log.Fatalf("Error running Wish server: %v", err)
 		}
 	} else {
 		// Run as CLI
 		p := tea.NewProgram(
-			NewModel("local", store),
+			NewModel(context.Background(), "local", store, client, nil, cfg),
 			tea.WithInput(os.Stdin),
 			tea.WithOutput(os.Stdout),
 		)
@@ -1,22 +1,38 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"mcli/internal/api"
 	"mcli/internal/cmdprompt"
+	"mcli/internal/columns"
+	"mcli/internal/config"
+	"mcli/internal/eventpool"
+	"mcli/internal/geo"
 	"mcli/internal/profile"
+	"mcli/internal/source"
 	"mcli/internal/tui"
 	"mcli/internal/tui/styles"
 	"mcli/internal/types"
 	"mcli/internal/utils"
-	"net/url"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// viewMode selects how the event list is drawn
+type viewMode int
+
+const (
+	viewTable viewMode = iota
+	viewAgenda
+	viewCalendar
+)
+
 // termSize holds the terminal dimensions
 type termSize struct {
 	height int
@@ -25,64 +41,258 @@ type termSize struct {
 
 // model represents the application state
 type model struct {
-	userID    string // SSH key fingerprint or "local" for CLI mode
-	profile   *profile.UserProfile
-	store     *profile.Store
-	Events    types.Events
-	table     tui.Table
-	sidebar   tui.Sidebar
-	statusbar tui.StatusBar
-	cmdPrompt *cmdprompt.CommandPrompt
-	filter        tui.Filter
-	termSize      termSize
-	bookmarksOnly bool
-	unreadOnly    bool
-	loading       bool
-	err           error
+	ctx            context.Context // cancelled on quit to abort in-flight requests
+	cancel         context.CancelFunc
+	client         *api.Client
+	pool           *eventpool.Pool // shared event list in wish mode, nil when running locally
+	userID         string          // SSH key fingerprint or "local" for CLI mode
+	profile        *profile.UserProfile
+	store          *profile.Store
+	Events         types.Events               // baseEvents and extraEvents merged, as displayed
+	baseEvents     types.Events               // from mcli.d (client, pool or cache)
+	extraEvents    types.Events               // from the other enabled providers
+	located        map[types.EventId]location // coordinates of Events, resolved by mergeEvents
+	table          tui.Table
+	agenda         tui.Agenda   // alternative view of the table's rows, sharing its cursor
+	calendar       tui.Calendar // month grid, its selection drives the table's cursor
+	view           viewMode
+	sidebar        tui.Sidebar
+	picker         tui.Picker // saved searches, opened with :search
+	statusbar      tui.StatusBar
+	cmdPrompt      *cmdprompt.CommandPrompt
+	keys           config.Keymap
+	filter         tui.Filter
+	termSize       termSize
+	bookmarksOnly  bool
+	unreadOnly     bool
+	nearKm         float64              // :near radius around the profile location, 0 shows all
+	zone           *time.Location       // time zone dates are shown and days are cut in
+	window         api.Window           // span of days listed
+	showPast       bool                 // list events before the window (dimmed) instead of hiding them
+	sort           tui.Sort             // table order, the agenda and calendar always go by date
+	defaultColumns string               // table columns from the config, used when the profile has none
+	sessionZone    *time.Location       // guessed from the SSH session's TZ, used when the profile has none
+	offline        bool                 // never call the API, show cached events only
+	fromCache      bool                 // Events came from the cache and haven't been refreshed yet
+	cachedAt       time.Time            // when the cached events were fetched
+	pageSize       int                  // events per /events page, 0 fetches everything at once
+	query          api.EventQuery       // what baseEvents were (or are being) fetched with
+	nextCursor     string               // cursor of the next page, empty when all are loaded
+	loadingMore    bool                 // a next page request is in flight
+	pageCursor     string               // cursor of the page in flight
+	serverText     string               // search text the loaded events were queried with
+	missedText     string               // search text the server found nothing for, filtered locally instead
+	defaultSources []types.SourceSpec   // providers from the config
+	mclid          bool                 // mcli.d is an enabled source for this user
+	extraSources   []source.EventSource // enabled providers other than mcli.d
+	sourceHosts    []string             // hosts SSH users may add calendar feeds from
+	publicHTTP     *http.Client         // fetches SSH users' own feeds, public addresses only
+	calendarURL    string               // base of the :calendar-link feeds, empty when not served
+	loading        bool
+	err            error
 }
 
 // NewModel initializes the application model with a user identity
-func NewModel(userID string, store *profile.Store) model {
+// pool is optional; when set, events come from it instead of per-session fetches.
+func NewModel(ctx context.Context, userID string, store *profile.Store, client *api.Client, pool *eventpool.Pool, cfg *config.Config) model {
 	p, err := store.Load(userID)
 	if err != nil {
 		utils.Logger.Error("failed to load profile", "userID", userID, "err", err)
 		p = profile.New(userID)
 	}
 	utils.Logger.Info("profile loaded", "userID", userID, "location", p.Location)
+	// locations saved before coordinates existed
+	if p.Coords.IsZero() && p.Location != "" {
+		if c, ok := geo.Lookup(p.Location); ok {
+			p.Coords = c.Point
+		}
+	}
 
-	return model{
-		userID:    userID,
-		profile:   p,
-		store:     store,
-		loading:   true,
-		table:     tui.NewTable(types.Events{}),
-		sidebar:   tui.NewSidebar(),
-		filter:    tui.NewFilter(),
-		cmdPrompt: cmdprompt.New(":", nil),
-		statusbar: tui.NewStatusBar("Press 'q' to quit, '/' to filter, 'y' for deatils", "", 80),
+	// render cached events right away, the API refresh happens in Init
+	var events types.Events
+	var cachedAt time.Time
+	fromCache := false
+	if pool != nil {
+		events, cachedAt = pool.Snapshot()
+	} else {
+		cached, fetchedAt, err := store.LoadEvents()
+		if err != nil {
+			utils.Logger.Error("failed to load cached events", "err", err)
+		}
+		events, cachedAt, fromCache = cached, fetchedAt, len(cached) > 0
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	m := model{
+		ctx:            ctx,
+		cancel:         cancel,
+		client:         client,
+		pool:           pool,
+		userID:         userID,
+		profile:        p,
+		store:          store,
+		Events:         events,
+		baseEvents:     events,
+		defaultSources: cfg.Sources,
+		sourceHosts:    cfg.SourceHosts,
+		defaultColumns: strings.Join(cfg.Columns, ","),
+		offline:        cfg.Offline,
+		fromCache:      fromCache,
+		cachedAt:       cachedAt,
+		pageSize:       cfg.PageSize,
+		loading:        len(events) == 0 && !cfg.Offline,
+		table:          tui.NewTable(types.Events{}),
+		agenda:         tui.NewAgenda(),
+		calendar:       tui.NewCalendar(),
+		sidebar:        tui.NewSidebar(),
+		picker:         tui.NewPicker(),
+		filter:         tui.NewFilter(),
+		cmdPrompt:      cmdprompt.New(":", nil),
+		keys:           cfg.Keys,
+		statusbar: tui.NewStatusBar(fmt.Sprintf("Press '%s' to quit, '%s' to filter, '%s' for deatils",
+			cfg.Keys.Key(config.ActionQuit), cfg.Keys.Key(config.ActionFilter), cfg.Keys.Key(config.ActionDetails)), "", 80),
+	}
+	if userID != "local" {
+		m.publicHTTP = source.PublicHTTPClient(cfg.HTTPTimeout.Duration)
 	}
+	m.zone = p.Zone()
+	m.window, err = api.ParseWindow(p.Window)
+	if err != nil {
+		utils.Logger.Error("ignoring saved window", "userID", userID, "window", p.Window, "err", err)
+	}
+	m.showPast = p.ShowPast
+	m.sort, err = tui.ParseSort(p.Sort)
+	if err != nil {
+		utils.Logger.Error("ignoring saved sort", "userID", userID, "sort", p.Sort, "err", err)
+		m.sort = tui.Sort{Key: tui.SortDate}
+	}
+	m.table.SetSort(m.sort)
+	layout, err := columns.Parse(m.columnsSetting())
+	if err != nil {
+		utils.Logger.Error("ignoring saved columns", "userID", userID, "columns", p.Columns, "err", err)
+		layout, _ = columns.Parse(m.defaultColumns)
+	}
+	m.table.SetLayout(layout)
+	m.statusbar.Window = m.windowStatus()
+	m.query = m.eventQuery()
+	m.mergeEvents()
+	m.loadSources()
+	// nothing to wait for without any source
+	m.loading = m.loading && (m.mclid || len(m.extraSources) > 0)
+	return m
 }
 
-// Init starts the application by fetching events
+// Init starts the application by fetching events, unless running offline
 func (m model) Init() tea.Cmd {
 	utils.Logger.Debug("Init Called", "userID", m.userID)
 	m.cmdPrompt.Init()
-	return api.FetchEventCmd
+	if m.offline {
+		return nil
+	}
+	// the pool refreshes on its own schedule and pushes updates
+	var fetch tea.Cmd
+	if m.mclid && m.pool == nil {
+		fetch = m.client.FetchEventCmd(m.ctx, m.query)
+	}
+	return tea.Batch(fetch, m.fetchSourcesCmd())
 }
 
 // Update handles incoming messages and updates the model
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case api.RetryMsg:
+		utils.Logger.Debug("update/api.RetryMsg", "attempt", msg.Attempt)
+		m.statusbar.NetStatus = tui.NetStatus(&msg.RetryEvent, m.client.Breaker.State())
+		return m, msg.Next
+
 	case api.FetchErrorMsg:
 		utils.Logger.Debug("update/tea.FetchErrorMsg")
+		m.statusbar.NetStatus = tui.NetStatus(nil, m.client.Breaker.State())
 		m.loading = false
+		m.loadingMore = false
 		m.err = msg.Err
+		utils.Logger.Error("fetch failed", "err", msg.Err)
+		// keep showing the last good list, report the failure in the prompt
+		if len(m.Events) > 0 {
+			m.cmdPrompt.SetOutput(fmt.Sprintf("%s, press '%s' to retry", tui.DescribeError(msg.Err), m.keys.Key(config.ActionRefresh)))
+		}
 		return m, nil
 
 	case api.FetchSuccessMsg:
 		utils.Logger.Debug("update/tea.FetchSuccessMsg")
+		if !msg.Query.Same(m.query) {
+			utils.Logger.Debug("dropping events of an older query")
+			return m, nil
+		}
+		// the server doesn't match fuzzily, a typo finds nothing there;
+		// load the whole list and let the filter match it here
+		if msg.Query.Text != "" && len(msg.Events) == 0 && msg.NextCursor == "" {
+			utils.Logger.Debug("no server matches, filtering locally", "text", msg.Query.Text)
+			m.missedText = msg.Query.Text
+			return m, m.refreshCmd()
+		}
+		m.statusbar.NetStatus = tui.NetStatus(nil, m.client.Breaker.State())
+		m.loading = false
+		m.err = nil
+		m.baseEvents = msg.Events
+		m.mergeEvents()
+		m.nextCursor = msg.NextCursor
+		m.fromCache = false
+		m.cachedAt = time.Now()
+		m.cacheEvents()
+		m.AdjustViewports()
+		return m, nil
+
+	case api.FetchPageMsg:
+		utils.Logger.Debug("update/api.FetchPageMsg", "events", len(msg.Events))
+		// a page of a list that has since been refetched
+		if !m.loadingMore || msg.Query.Cursor != m.pageCursor || !msg.Query.Same(m.query) {
+			utils.Logger.Debug("dropping a stale page", "cursor", msg.Query.Cursor)
+			return m, nil
+		}
+		m.loadingMore = false
+		m.nextCursor = msg.NextCursor
+		// copy so a slice shared with the client cache is never appended to
+		m.baseEvents = append(append(types.Events{}, m.baseEvents...), msg.Events...)
+		m.mergeEvents()
+		m.cacheEvents()
+		m.AdjustViewports()
+		return m, nil
+
+	case api.FetchJobMsg:
+		utils.Logger.Debug("update/api.FetchJobMsg", "status", msg.Job.Status, "new", msg.Job.NewEvents)
+		m.cmdPrompt.SetOutput(tui.JobProgress(msg.Job, msg.Err))
+		switch {
+		case msg.Err != nil || msg.Job.Status == api.JobFailed:
+			return m, nil
+		case !msg.Job.Done():
+			return m, m.client.PollFetchJobCmd(m.ctx, msg.Job)
+		}
+		// scrape finished, pick up the new events
+		return m, m.refreshCmd()
+
+	case source.FetchedMsg:
+		utils.Logger.Debug("update/source.FetchedMsg", "events", len(msg.Events), "errors", len(msg.Errors))
+		m.loading = false
+		m.extraEvents = msg.Events
+		m.mergeEvents()
+		if len(msg.Errors) > 0 {
+			m.cmdPrompt.SetOutput(fmt.Sprintf("Some sources failed: %v", msg.Errors[0]))
+		}
+		m.AdjustViewports()
+		return m, nil
+
+	case eventpool.UpdatedMsg:
+		utils.Logger.Debug("update/eventpool.UpdatedMsg", "events", len(msg.Events))
+		if !m.mclid {
+			return m, nil
+		}
+		m.statusbar.NetStatus = tui.NetStatus(nil, m.client.Breaker.State())
 		m.loading = false
-		m.Events = msg.Events
+		m.err = nil
+		m.baseEvents = msg.Events
+		m.mergeEvents()
+		m.cachedAt = msg.FetchedAt
 		m.AdjustViewports()
 		return m, nil
 
@@ -97,29 +307,51 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyMsg:
 		utils.Logger.Info("update/key pressed", "key", msg.String())
+		if m.picker.IsVisible() {
+			return m.pickerKey(msg)
+		}
 		if m.filter.IsFiltering() {
 			switch msg.String() {
 			case "esc":
 				m.filter.ToggleFilterView()
-				m.filter.Text = ""
-				m.table.SetRows(tui.CreateTableRows(m.DisplayedEvents(""), m.profile.IsBookmarked, m.profile.IsRead))
+				m.filter.SetText("")
+				m.setRows()
 				m.statusbar.FilteredText = "" // Clear filter text
 				m.AdjustViewports()
+				// drop a server side search
+				if m.serverText != "" {
+					return m, m.refreshCmd()
+				}
 			case "enter":
+				// stay in the filter bar until the query parses
+				m.filter.SetText(m.filter.Input.Value())
+				if m.filter.Err != nil {
+					return m, nil
+				}
+				// back in date order, on the event that was selected
+				selected, ok := m.selectedEvent()
 				m.filter.ToggleFilterView()
-				m.filter.Text = m.filter.Input.Value()
-				m.table.SetRows(tui.CreateTableRows(m.DisplayedEvents(m.filter.Text), m.profile.IsBookmarked, m.profile.IsRead))
+				m.setRows()
+				if ok {
+					m.selectEvent(selected.ID)
+				}
 				filterText := m.filter.Text
 				if filterText != "" {
 					filterText = "/" + filterText
 				}
 				m.statusbar.FilteredText = filterText // Update filter text
 				m.AdjustViewports()
+				return m, m.filterRefreshCmd()
 			default:
 				var cmd tea.Cmd
 				m.filter, cmd = m.filter.Update(msg)
-				m.filter.Text = m.filter.Input.Value()
-				m.table.SetRows(tui.CreateTableRows(m.DisplayedEvents(m.filter.Text), m.profile.IsBookmarked, m.profile.IsRead))
+				previous := m.filter.Text
+				m.filter.SetText(m.filter.Input.Value())
+				// the ranking changed, start from the best match
+				if m.filter.Text != previous && m.view == viewTable {
+					m.table.SetCursor(0)
+				}
+				m.setRows()
 				utils.Logger.Info("filtering list", "text", m.filter.Text)
 				return m, cmd
 			}
@@ -127,8 +359,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		if m.sidebar.IsVisible() {
-			switch msg.String() {
-			case "q", "esc":
+			if msg.String() == "esc" || m.keys.Action(msg.String()) == config.ActionQuit {
 				m.sidebar.ToggleSidebarView()
 				m.sidebar.Viewport.GotoTop()
 				m.AdjustViewports()
@@ -142,28 +373,42 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if consumed {
 			// If CommandPrompt handled the message, return early
 			if m.cmdPrompt.GetOutput() == "Quitting..." {
+				m.cancel()
 				return m, tea.Quit
 			}
 			return m, _cmd
 		}
 
-		switch msg.String() {
-		case "ctrl+c", "q":
+		if msg.String() == "ctrl+c" {
+			m.cancel()
+			return m, tea.Quit
+		}
+
+		if m.view == viewCalendar {
+			if handled, cmd := m.calendarKey(msg); handled {
+				return m, cmd
+			}
+		}
+
+		switch m.keys.Action(msg.String()) {
+		case config.ActionQuit:
+			m.cancel()
 			return m, tea.Quit
-		case "y":
+		case config.ActionDetails:
 			m.sidebar.ToggleSidebarView()
 			// mark event as read when opening sidebar
-			if m.sidebar.IsVisible() {
-				events := m.DisplayedEvents(m.filter.Text)
-				if len(events) > 0 {
-					event := events[m.table.Cursor()]
-					m.profile.MarkRead(event.ID)
-					m.store.AddReadEvent(m.userID, event.ID)
-				}
+			event, ok := m.selectedEvent()
+			if m.sidebar.IsVisible() && ok {
+				m.profile.MarkRead(event.ID)
+				m.store.AddReadEvent(m.userID, event.ID)
 			}
 			// always render viewport from top
 			m.sidebarMovement(msg)
-		case "/":
+			// sorted by unread, the event may have moved
+			if ok {
+				m.selectEvent(event.ID)
+			}
+		case config.ActionFilter:
 			utils.Logger.Info("Filtering the entries")
 			m.filter.ToggleFilterView()
 			m.AdjustViewports()
@@ -172,48 +417,77 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, textinput.Blink
 			}
 			return m, nil
-		case "r":
-			return m, api.FetchEventCmd
+		case config.ActionRefresh:
+			if m.offline {
+				m.cmdPrompt.SetOutput("Offline mode, showing cached events")
+				return m, nil
+			}
+			// only blank the screen when there is no list to keep showing
+			m.loading = len(m.Events) == 0
+			return m, m.refreshCmd()
 
-		case "b":
+		case config.ActionBookmark:
 			// toggle bookmark on current event
-			events := m.DisplayedEvents(m.filter.Text)
-			if len(events) > 0 {
-				event := events[m.table.Cursor()]
+			if event, ok := m.selectedEvent(); ok {
 				added := m.profile.ToggleBookmark(event.ID)
 				if added {
-					m.store.AddBookmark(m.userID, event.ID)
+					m.store.AddBookmark(m.userID, event)
 				} else {
 					m.store.RemoveBookmark(m.userID, event.ID)
 				}
 				m.AdjustViewports()
+				m.selectEvent(event.ID)
 			}
 			return m, nil
 
-		case "o":
+		case config.ActionOpen:
 			// open link in browser and mark as read
-			events := m.DisplayedEvents(m.filter.Text)
-			if len(events) > 0 {
-				event := events[m.table.Cursor()]
+			if event, ok := m.selectedEvent(); ok {
 				utils.OpenURL(event.Url)
 				m.profile.MarkRead(event.ID)
 				m.store.AddReadEvent(m.userID, event.ID)
 				m.AdjustViewports()
+				m.selectEvent(event.ID)
 			}
 			return m, nil
 
-		case "h":
+		case config.ActionAgenda:
+			m.toggleView(viewAgenda)
+			return m, nil
+
+		case config.ActionCalendar:
+			m.toggleView(viewCalendar)
+			return m, nil
+
+		case config.ActionSort:
+			m.cmdPrompt.SetOutput(m.setSort(m.sort.Next()))
+			return m, nil
+
+		case config.ActionReverse:
+			m.cmdPrompt.SetOutput(m.setSort(tui.Sort{Key: m.sort.Key, Desc: !m.sort.Desc}))
+			return m, nil
+
+		case config.ActionWindow:
+			m.cmdPrompt.SetOutput(m.setWindow(m.window.Next(), m.showPast))
+			return m, m.windowRefreshCmd()
+
+		case config.ActionPast:
+			m.cmdPrompt.SetOutput(m.setWindow(m.window, !m.showPast))
+			return m, m.windowRefreshCmd()
+
+		case config.ActionNext:
 			m.table.MoveDown(1)
 			if m.sidebar.IsVisible() {
 				m.sidebarMovement(msg)
 			}
 
-		case "l":
+		case config.ActionPrev:
 			m.table.MoveUp(1)
 			if m.sidebar.IsVisible() {
 				m.sidebarMovement(msg)
 			}
 		}
+		m.agenda.SetCursor(m.table.Cursor())
 
 		var cmd tea.Cmd
 		if m.sidebar.IsVisible() {
@@ -222,26 +496,121 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		m.table.Model, cmd = m.table.Update(msg)
-		return m, cmd
+		m.agenda.SetCursor(m.table.Cursor())
+		return m, tea.Batch(cmd, m.loadMoreCmd())
 	}
 
 	return m, nil
 }
 
+// filterRefreshCmd searches on the server for filter words the loaded
+// events may lack, nil when the loaded events are enough
+func (m *model) filterRefreshCmd() tea.Cmd {
+	// events not loaded yet can only be searched on the server
+	if m.searchText() != m.serverText && (m.nextCursor != "" || m.serverText != "") {
+		return m.refreshCmd()
+	}
+	return nil
+}
+
+// refreshCmd refetches events, through the shared pool in wish mode
+func (m *model) refreshCmd() tea.Cmd {
+	var fetch tea.Cmd
+	switch {
+	case !m.mclid:
+	case m.pool != nil:
+		fetch = m.pool.RefreshCmd()
+	default:
+		m.query = m.eventQuery()
+		m.serverText = m.query.Text
+		// pages in flight belong to the list being replaced
+		m.loadingMore = false
+		fetch = m.client.FetchEventCmd(m.ctx, m.query)
+	}
+	return tea.Batch(fetch, m.fetchSourcesCmd())
+}
+
+// cacheEvents saves the mcli.d events for --offline once all of them are
+// loaded; searches, windows, locations and partial lists would replace the
+// full cache
+func (m *model) cacheEvents() {
+	if !m.query.Unfiltered() || m.nextCursor != "" {
+		return
+	}
+	if err := m.store.SaveEvents(m.baseEvents, m.cachedAt); err != nil {
+		utils.Logger.Error("failed to cache events", "err", err)
+	}
+}
+
+// eventQuery is the server side query for this user's event list
+func (m model) eventQuery() api.EventQuery {
+	from, to := m.window.Bounds(time.Now(), m.zone)
+	if m.showPast {
+		from = time.Time{}
+	}
+	return api.EventQuery{
+		Location: m.profile.Location,
+		From:     from,
+		To:       to,
+		Text:     m.searchText(),
+		Limit:    m.pageSize,
+	}
+}
+
+// searchText is the filter text to search for on the server, empty once
+// the server found nothing for it
+func (m model) searchText() string {
+	if text := m.filter.Query.Text(); text != m.missedText {
+		return text
+	}
+	return ""
+}
+
+// loadMoreCmd requests the next page once the cursor gets close to the end of the table
+func (m *model) loadMoreCmd() tea.Cmd {
+	const threshold = 5 // rows from the end
+	if m.pool != nil || m.offline || !m.mclid || m.nextCursor == "" || m.loadingMore {
+		return nil
+	}
+	if m.table.Cursor() < len(m.table.Rows())-threshold {
+		return nil
+	}
+	m.loadingMore = true
+	m.pageCursor = m.nextCursor
+	return m.client.FetchNextPageCmd(m.ctx, m.query, m.nextCursor)
+}
+
 // View renders the current state of the application
 func (m model) View() string {
 	if m.loading {
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Render("Loading...")
+		loading := "Loading..."
+		if m.statusbar.NetStatus != "" {
+			loading += " " + m.statusbar.NetStatus
+		}
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Render(loading)
 	}
-	if m.err != nil {
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("Error: " + m.err.Error())
+	if m.err != nil && len(m.Events) == 0 {
+		errorView := tui.ErrorView(m.err, m.keys.Key(config.ActionRefresh))
+		return styles.BaseStyle.Render(lipgloss.JoinVertical(lipgloss.Left, errorView, "", m.cmdPrompt.View()))
 	}
 	if len(m.Events) == 0 {
+		if m.offline {
+			return lipgloss.NewStyle().Foreground(lipgloss.Color("3")).Render("No cached events, run once without --offline\n")
+		}
 		return lipgloss.NewStyle().Foreground(lipgloss.Color("3")).Render("No events found\n")
 	}
 
 	// start from table rendering
 	renderedView := m.table.View()
+	switch m.view {
+	case viewAgenda:
+		renderedView = m.agenda.View()
+	case viewCalendar:
+		renderedView = m.calendar.View()
+	}
+	if m.picker.IsVisible() {
+		renderedView = m.picker.View()
+	}
 
 	// if user is filtering the text
 	if m.filter.IsFiltering() {
@@ -258,15 +627,17 @@ func (m model) View() string {
 	renderedView = lipgloss.JoinVertical(lipgloss.Left, renderedView, cmdBarView)
 
 	// Add status bar
-	statusBarView := m.statusbar.View()
+	statusbar := m.statusbar
+	statusbar.CacheStatus = tui.CacheStatus(m.cachedAt, m.offline, m.fromCache)
+	statusBarView := statusbar.View()
 	renderedView = lipgloss.JoinVertical(lipgloss.Left, renderedView, statusBarView)
 
 	return styles.BaseStyle.Render(renderedView)
 }
 
 // DisplayedEvents returns the current list of events based on active filters
-func (m model) DisplayedEvents(filter string) []types.Event {
-	events := m.Events
+func (m model) DisplayedEvents() []types.Event {
+	events := m.windowEvents()
 
 	// Apply bookmarks-only filter
 	if m.bookmarksOnly {
@@ -279,6 +650,17 @@ func (m model) DisplayedEvents(filter string) []types.Event {
 		events = bookmarked
 	}
 
+	// Apply :near radius
+	if m.nearKm > 0 && !m.profile.Coords.IsZero() {
+		var near []types.Event
+		for _, e := range events {
+			if l := m.located[e.ID]; l.ok && geo.Distance(m.profile.Coords, l.p) <= m.nearKm {
+				near = append(near, e)
+			}
+		}
+		events = near
+	}
+
 	// Apply unread-only filter
 	if m.unreadOnly {
 		var unread []types.Event
@@ -290,10 +672,60 @@ func (m model) DisplayedEvents(filter string) []types.Event {
 		events = unread
 	}
 
-	if filter == "" {
-		return events
+	// the agenda and the calendar go by date
+	if m.view != viewTable {
+		return m.filter.Apply(events, m.zone)
 	}
-	return tui.FilterEvents(events, filter)
+	if !m.filter.IsFiltering() {
+		return m.sort.Apply(m.filter.Apply(events, m.zone), m.rowContext())
+	}
+	// best matches first while the query is being typed
+	events, scores := m.filter.ApplyScored(events, m.zone)
+	return tui.RankEvents(m.sort.Apply(events, m.rowContext()), scores)
+}
+
+// setRows fills the visible view with the displayed events; toggleView
+// fills the others when they are switched to
+func (m *model) setRows() {
+	events := m.DisplayedEvents()
+	switch m.view {
+	case viewAgenda:
+		m.table.SetPlaceholders(len(events))
+		m.agenda.SetEvents(events, m.rowContext())
+		m.agenda.SetCursor(m.table.Cursor())
+	case viewCalendar:
+		m.table.SetPlaceholders(len(events))
+		m.calendar.SetEvents(events, m.zone)
+	default:
+		m.table.SetEvents(events, m.rowContext())
+	}
+}
+
+func (m model) rowContext() tui.RowContext {
+	return tui.RowContext{
+		IsBookmarked: m.profile.IsBookmarked,
+		IsRead:       m.profile.IsRead,
+		Distance:     m.distance,
+		Match:        m.filter.Query.Highlight,
+		Zone:         m.zone,
+	}
+}
+
+// distance renders how far an event is from the profile location,
+// prefixed with ~ when only the venue's city is known
+func (m model) distance(e types.Event) string {
+	if m.profile.Coords.IsZero() {
+		return ""
+	}
+	l := m.located[e.ID]
+	if !l.ok {
+		return ""
+	}
+	d := geo.FormatDistance(geo.Distance(m.profile.Coords, l.p))
+	if !l.exact {
+		d = "~" + d
+	}
+	return d
 }
 
 func (m *model) AdjustViewports() {
@@ -308,7 +740,6 @@ func (m *model) AdjustViewports() {
 	// Calculate table height
 	tableHeight := m.termSize.height - statusbarHeight - filterHeight - 2 // 2 for border(head/tail)
 	m.table.SetHeight(tableHeight)
-	m.table.SetRows(tui.CreateTableRows(m.DisplayedEvents(m.filter.Text), m.profile.IsBookmarked, m.profile.IsRead))
 
 	// Calculate table width
 	m.statusbar.Width = m.termSize.width - 2
@@ -322,8 +753,13 @@ func (m *model) AdjustViewports() {
 
 	}
 	m.table.SetWidth(tableWidth)
-	m.table.AdjustColumns(tableWidth, m.sidebar.IsVisible())
-
+	m.table.SetShowDistance(!m.profile.Coords.IsZero())
+	m.table.AdjustColumns()
+	m.agenda.SetSize(tableWidth, tableHeight)
+	m.calendar.SetSize(tableWidth, tableHeight)
+	m.picker.SetSize(tableWidth, tableHeight)
+	// rows are fitted to the column widths
+	m.setRows()
 }
 
 // DebugLayout logs the current layout dimensions for debugging
@@ -337,11 +773,9 @@ func (m *model) DebugLayout() {
 func (m *model) sidebarMovement(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	m.sidebar.Viewport.GotoTop()
-	filteredEvents := m.DisplayedEvents(m.filter.Text)
-	if m.sidebar.IsVisible() && len(filteredEvents) > 0 {
-		cursor := m.table.Cursor()
-		event := filteredEvents[cursor]
-		m.sidebar.UpdateSidebarContent(event, m.termSize.height)
+	if event, ok := m.selectedEvent(); m.sidebar.IsVisible() && ok {
+		m.sidebar.Match = m.filter.Query.Highlight
+		m.sidebar.UpdateSidebarContent(event, m.termSize.height, m.zone)
 		utils.Logger.Info("Inspecting details on", "event", event.ID)
 	}
 	m.AdjustViewports()
@@ -353,7 +787,7 @@ func (m *model) sidebarMovement(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m *model) handleCommand(command string) (string, tea.Cmd) {
 
-	var availableOpts = []string{"refresh", "fetch", "set-location", "bookmarks", "unread", "quit", "help"}
+	var availableOpts = []string{"refresh", "fetch", "set-location", "set-timezone", "window", "near", "bookmarks", "unread", "sources", "source", "export", "calendar-link", "save-search", "search", "sort", "columns", "quit", "help"}
 	_cmd := strings.Split(command, " ")
 	switch strings.ToLower(_cmd[0]) {
 	case "":
@@ -371,14 +805,65 @@ func (m *model) handleCommand(command string) (string, tea.Cmd) {
 			if m.profile.Location != "" {
 				return fmt.Sprintf("Current location: %s", m.profile.Location), nil
 			}
-			return "Usage: set-location <city>", nil
+			return "Usage: set-location <city> or <lat,lon>", nil
 		}
-		m.profile.Location = args
-		if err := m.store.SaveLocation(m.userID, args); err != nil {
+		location, coords := resolveLocation(args)
+		m.profile.Location = location
+		m.profile.Coords = coords
+		if err := m.store.SaveLocation(m.userID, location, coords); err != nil {
 			utils.Logger.Error("failed to save location", "err", err)
 			return "Failed to save location", nil
 		}
-		return fmt.Sprintf("Location set to: %s", args), nil
+		msg := fmt.Sprintf("Location set to: %s (%s)", location, coords)
+		if coords.IsZero() {
+			m.nearKm = 0
+			msg = fmt.Sprintf("Location set to: %s (unknown city, distances unavailable)", location)
+		}
+		m.AdjustViewports()
+		if m.offline {
+			return msg, nil
+		}
+		return msg, m.refreshCmd()
+	case "set-timezone":
+		return m.setTimezone(strings.TrimSpace(strings.Join(_cmd[1:], " "))), nil
+	case "window":
+		return m.handleWindowCommand(strings.TrimSpace(strings.Join(_cmd[1:], " ")))
+	case "near":
+		args := strings.TrimSpace(strings.Join(_cmd[1:], " "))
+		switch {
+		case args == "":
+			if m.nearKm > 0 {
+				return fmt.Sprintf("Showing events within %s of %s", geo.FormatDistance(m.nearKm), m.profile.Location), nil
+			}
+			return "Usage: near <radius>|off, e.g. near 5km", nil
+		case args == "off":
+			m.nearKm = 0
+			m.AdjustViewports()
+			return "Showing events at any distance", nil
+		case m.profile.Coords.IsZero():
+			return "Set a known location first: set-location <city> or <lat,lon>", nil
+		}
+		km, err := geo.ParseRadius(args)
+		if err != nil {
+			return err.Error(), nil
+		}
+		m.nearKm = km
+		m.AdjustViewports()
+		return fmt.Sprintf("Showing events within %s of %s", geo.FormatDistance(km), m.profile.Location), nil
+	case "sources", "source":
+		return m.handleSourceCommand(_cmd[1:])
+	case "export":
+		return m.handleExportCommand(_cmd[1:])
+	case "calendar-link":
+		return m.handleCalendarLinkCommand(strings.TrimSpace(strings.Join(_cmd[1:], " "))), nil
+	case "sort":
+		return m.handleSortCommand(strings.TrimSpace(strings.Join(_cmd[1:], " "))), nil
+	case "columns":
+		return m.handleColumnsCommand(strings.TrimSpace(strings.Join(_cmd[1:], " "))), nil
+	case "save-search":
+		return m.handleSaveSearchCommand(strings.TrimSpace(strings.Join(_cmd[1:], " "))), nil
+	case "search":
+		return m.handleSearchCommand(strings.TrimSpace(strings.Join(_cmd[1:], " ")))
 	case "bookmarks":
 		m.bookmarksOnly = !m.bookmarksOnly
 		m.AdjustViewports()
@@ -402,14 +887,80 @@ func (m *model) handleCommand(command string) (string, tea.Cmd) {
 		if args == "" {
 			return "No location set. Use :set-location <city> first", nil
 		}
-		return fmt.Sprintf("Fetching events for %s", args), FetchEventByLocation(url.PathEscape(args))
+		if m.offline {
+			return "Offline mode, can't fetch new events", nil
+		}
+		if !m.mclid {
+			return "The mcli.d source is disabled, enable it with :source enable mcli.d", nil
+		}
+		return fmt.Sprintf("Fetching events for %s", args), m.client.FetchEventByLocationCmd(m.ctx, args)
 	default:
 		return fmt.Sprintf("Unknown command: %s", command), nil
 	}
 }
 
-func FetchEventByLocation(loc string) tea.Cmd {
-	return func() tea.Msg {
-		return api.FetchEventByLocationCmd(loc)
+// resolveLocation turns :set-location input into a city name and coordinates
+// using the offline gazetteer. Coordinates are named after the nearest city so
+// the backend still gets a place to search; unknown names keep zero coordinates.
+func resolveLocation(input string) (string, geo.Point) {
+	if p, err := geo.ParsePoint(input); err == nil {
+		if c, km := geo.Nearest(p); km <= 50 {
+			return c.Name, p
+		}
+		return input, p
+	}
+	if c, ok := geo.Lookup(input); ok {
+		return c.Name, c.Point
 	}
+	return input, geo.Point{}
+}
+
+// UseSessionTimezone adopts the zone an SSH client sent in TZ, unless the
+// user saved one with :set-timezone
+func (m *model) UseSessionTimezone(tz string) {
+	loc, err := time.LoadLocation(strings.TrimPrefix(tz, ":"))
+	if tz == "" || err != nil {
+		return
+	}
+	m.sessionZone = loc
+	if m.profile.Timezone == "" {
+		m.zone = loc
+		m.mergeEvents()
+	}
+}
+
+// setTimezone implements :set-timezone [<zone>|local]
+func (m *model) setTimezone(name string) string {
+	if name == "" {
+		switch {
+		case m.profile.Timezone != "":
+			return fmt.Sprintf("Time zone: %s", m.profile.Timezone)
+		case m.sessionZone != nil:
+			return fmt.Sprintf("Time zone: %s (from TZ)", m.sessionZone)
+		}
+		return fmt.Sprintf("Time zone: %s (server). Usage: set-timezone <Area/City>|local", time.Local)
+	}
+
+	loc := m.sessionZone
+	if loc == nil {
+		loc = time.Local
+	}
+	if strings.EqualFold(name, "local") {
+		name = ""
+	} else {
+		var err error
+		if loc, err = time.LoadLocation(name); err != nil {
+			return fmt.Sprintf("Unknown time zone %q, use a name like Asia/Kathmandu", name)
+		}
+		name = loc.String()
+	}
+	if err := m.store.SaveTimezone(m.userID, name); err != nil {
+		utils.Logger.Error("failed to save time zone", "err", err)
+		return "Failed to save time zone"
+	}
+	m.profile.Timezone = name
+	m.zone = loc
+	m.mergeEvents()
+	m.AdjustViewports()
+	return fmt.Sprintf("Time zone set to: %s", loc)
 }
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"mcli/internal/api"
+	"mcli/internal/profile"
+	"mcli/internal/tui"
+	"mcli/internal/types"
+	"mcli/internal/utils"
+)
+
+// a typo the server finds nothing for still matches the loaded events
+func TestServerSearchTypo(t *testing.T) {
+	utils.InitLogger(false)
+	store, err := profile.OpenStore(filepath.Join(t.TempDir(), "mcli.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := model{
+		userID:  "local",
+		profile: profile.New("local"),
+		mclid:   true,
+		client:  api.NewClient("http://mcli.invalid"),
+		store:   store,
+		filter:  tui.NewFilter(),
+		zone:    npt,
+	}
+	m.filter.SetText("kubernets")
+	m.query = m.eventQuery()
+	m.serverText = m.query.Text
+	if m.query.Text != "kubernets" {
+		t.Fatalf("searched for %q, want kubernets", m.query.Text)
+	}
+
+	updated, cmd := m.Update(api.FetchSuccessMsg{Query: m.query})
+	m = updated.(model)
+	if cmd == nil || m.query.Text != "" {
+		t.Fatalf("no matches refetched with %q, want the whole list", m.query.Text)
+	}
+	// the filter doesn't search for the same text again
+	if cmd := m.filterRefreshCmd(); cmd != nil {
+		t.Error("filterRefreshCmd searched on the server again")
+	}
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(time.RFC3339)
+	events := types.Events{
+		{ID: "k8s", Title: "Kubernetes Kathmandu", DateTime: tomorrow},
+		{ID: "rust", Title: "Rust Meetup", DateTime: tomorrow},
+	}
+	updated, _ = m.Update(api.FetchSuccessMsg{Events: events, Query: m.query})
+	m = updated.(model)
+	var ids []types.EventId
+	for _, e := range m.DisplayedEvents() {
+		ids = append(ids, e.ID)
+	}
+	if want := []types.EventId{"k8s"}; !slices.Equal(ids, want) {
+		t.Errorf("displayed %v, want %v", ids, want)
+	}
+}
+
+// pages of a list that was refetched since they were asked for are dropped
+func TestStalePages(t *testing.T) {
+	utils.InitLogger(false)
+	store, err := profile.OpenStore(filepath.Join(t.TempDir(), "mcli.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(time.RFC3339)
+	m := model{
+		userID:      "local",
+		profile:     profile.New("local"),
+		mclid:       true,
+		client:      api.NewClient("http://mcli.invalid"),
+		store:       store,
+		filter:      tui.NewFilter(),
+		zone:        npt,
+		query:       api.EventQuery{Text: "rust", Limit: 2},
+		baseEvents:  types.Events{{ID: "1", DateTime: tomorrow}, {ID: "2", DateTime: tomorrow}},
+		nextCursor:  "c2",
+		loadingMore: true,
+		pageCursor:  "c2",
+	}
+	page := func(q api.EventQuery, cursor string) api.FetchPageMsg {
+		q.Cursor = cursor
+		return api.FetchPageMsg{Events: types.Events{{ID: types.EventId(cursor), DateTime: tomorrow}}, NextCursor: "c3", Query: q}
+	}
+
+	for name, msg := range map[string]api.FetchPageMsg{
+		"another search": page(api.EventQuery{Text: "go", Limit: 2}, "c2"),
+		"another cursor": page(m.query, "c9"),
+	} {
+		updated, _ := m.Update(msg)
+		if got := updated.(model); len(got.baseEvents) != 2 || got.nextCursor != "c2" {
+			t.Errorf("%s: kept the page, %d events, next %q", name, len(got.baseEvents), got.nextCursor)
+		}
+	}
+
+	updated, _ := m.Update(page(m.query, "c2"))
+	m = updated.(model)
+	if len(m.baseEvents) != 3 || m.nextCursor != "c3" || m.loadingMore {
+		t.Fatalf("dropped the page: %d events, next %q", len(m.baseEvents), m.nextCursor)
+	}
+	// the same page again, e.g. after a retry, isn't appended twice
+	updated, _ = m.Update(page(m.query, "c2"))
+	if got := updated.(model); len(got.baseEvents) != 3 {
+		t.Errorf("appended a page nobody waits for, %d events", len(got.baseEvents))
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"mcli/internal/api"
+	"mcli/internal/profile"
+	"mcli/internal/tui"
+	"mcli/internal/utils"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// handleSaveSearchCommand implements :save-search <name>, saving the filter,
+// time window and bookmarks/unread toggles under name
+func (m *model) handleSaveSearchCommand(name string) string {
+	if name == "" {
+		return "Usage: save-search <name>"
+	}
+	f := profile.SavedSearch{
+		Query:         m.filter.Text,
+		Window:        m.window.String(),
+		ShowPast:      m.showPast,
+		BookmarksOnly: m.bookmarksOnly,
+		UnreadOnly:    m.unreadOnly,
+	}
+	if err := m.store.SaveFilter(m.userID, name, f); err != nil {
+		utils.Logger.Error("failed to save search", "name", name, "err", err)
+		return "Failed to save search"
+	}
+	_, replaced := m.profile.Filters[name]
+	m.profile.Filters[name] = f
+	if replaced {
+		return fmt.Sprintf("Updated search %q: %s", name, searchDetail(f))
+	}
+	return fmt.Sprintf("Saved search %q: %s", name, searchDetail(f))
+}
+
+// handleSearchCommand implements :search [<name>], without a name it opens the picker
+func (m *model) handleSearchCommand(name string) (string, tea.Cmd) {
+	if name == "" {
+		if len(m.profile.Filters) == 0 {
+			return "No saved searches, save one with :save-search <name>", nil
+		}
+		m.picker.Open(m.pickerItems())
+		return "", nil
+	}
+	f, ok := m.profile.Filters[name]
+	if !ok {
+		return fmt.Sprintf("Unknown search %q, list them with :search", name), nil
+	}
+	return m.applySearch(name, f)
+}
+
+// applySearch replaces the filter, window and toggles with the saved ones
+func (m *model) applySearch(name string, f profile.SavedSearch) (string, tea.Cmd) {
+	w, err := api.ParseWindow(f.Window)
+	if err != nil {
+		return fmt.Sprintf("Search %q has an invalid window: %v", name, err), nil
+	}
+	m.filter.Input.SetValue(f.Query)
+	m.filter.SetText(f.Query)
+	if m.filter.Err != nil {
+		return fmt.Sprintf("Search %q no longer parses: %v", name, m.filter.Err), nil
+	}
+	m.bookmarksOnly, m.unreadOnly = f.BookmarksOnly, f.UnreadOnly
+	m.statusbar.FilteredText = ""
+	if m.filter.Text != "" {
+		m.statusbar.FilteredText = "/" + m.filter.Text
+	}
+	m.setWindow(w, f.ShowPast)
+
+	cmd := m.filterRefreshCmd()
+	if cmd == nil {
+		cmd = m.windowRefreshCmd()
+	}
+	return fmt.Sprintf("Search %q: %s", name, searchDetail(f)), cmd
+}
+
+// pickerItems lists the saved searches by name
+func (m model) pickerItems() []tui.PickerItem {
+	var items []tui.PickerItem
+	for _, name := range slices.Sorted(maps.Keys(m.profile.Filters)) {
+		items = append(items, tui.PickerItem{Name: name, Detail: searchDetail(m.profile.Filters[name])})
+	}
+	return items
+}
+
+// searchDetail summarizes what a saved search applies
+func searchDetail(f profile.SavedSearch) string {
+	parts := []string{"all events"}
+	if f.Query != "" {
+		parts[0] = "/" + f.Query
+	}
+	if w, err := api.ParseWindow(f.Window); err == nil && w.Preset != api.WindowUpcoming {
+		parts = append(parts, w.Label())
+	}
+	if f.ShowPast {
+		parts = append(parts, "+past")
+	}
+	if f.BookmarksOnly {
+		parts = append(parts, "bookmarks")
+	}
+	if f.UnreadOnly {
+		parts = append(parts, "unread")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// pickerKey handles keys while the saved search picker is open
+func (m *model) pickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if key == "ctrl+c" {
+		m.cancel()
+		return m, tea.Quit
+	}
+
+	switch {
+	case m.picker.IsRenaming():
+		switch key {
+		case "esc":
+			m.picker.StopRename()
+		case "enter":
+			from, _ := m.picker.Selected()
+			to := strings.TrimSpace(m.picker.Input.Value())
+			m.picker.StopRename()
+			if to == "" || to == from {
+				return m, nil
+			}
+			if err := m.store.RenameFilter(m.userID, from, to); err != nil {
+				utils.Logger.Error("failed to rename search", "from", from, "to", to, "err", err)
+				m.cmdPrompt.SetOutput("Failed to rename search")
+				return m, nil
+			}
+			m.profile.Filters[to] = m.profile.Filters[from]
+			delete(m.profile.Filters, from)
+			m.picker.SetItems(m.pickerItems())
+			m.picker.Select(to)
+			m.cmdPrompt.SetOutput(fmt.Sprintf("Renamed search %q to %q", from, to))
+		default:
+			var cmd tea.Cmd
+			m.picker.Input, cmd = m.picker.Input.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case m.picker.IsDeleting():
+		m.picker.ConfirmDelete(false)
+		name, ok := m.picker.Selected()
+		if key != "y" || !ok {
+			return m, nil
+		}
+		if err := m.store.RemoveFilter(m.userID, name); err != nil {
+			utils.Logger.Error("failed to delete search", "name", name, "err", err)
+			m.cmdPrompt.SetOutput("Failed to delete search")
+			return m, nil
+		}
+		delete(m.profile.Filters, name)
+		m.picker.SetItems(m.pickerItems())
+		m.cmdPrompt.SetOutput(fmt.Sprintf("Deleted search %q", name))
+		return m, nil
+	}
+
+	switch key {
+	case "esc", "q":
+		m.picker.Close()
+	case "j", "down":
+		m.picker.Move(1)
+	case "k", "up":
+		m.picker.Move(-1)
+	case "r":
+		m.picker.StartRename()
+		return m, textinput.Blink
+	case "d", "x":
+		m.picker.ConfirmDelete(true)
+	case "enter":
+		name, ok := m.picker.Selected()
+		if !ok {
+			return m, nil
+		}
+		m.picker.Close()
+		out, cmd := m.applySearch(name, m.profile.Filters[name])
+		m.cmdPrompt.SetOutput(out)
+		return m, cmd
+	}
+	return m, nil
+}
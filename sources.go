@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"mcli/internal/api"
+	"mcli/internal/geo"
+	"mcli/internal/source"
+	"mcli/internal/types"
+	"mcli/internal/utils"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// loadSources resolves this user's providers from the config defaults and the profile
+func (m *model) loadSources() {
+	m.mclid = false
+	m.extraSources = nil
+	for _, spec := range source.Resolve(m.defaultSources, m.profile.Sources) {
+		if !spec.Enabled {
+			continue
+		}
+		if spec.Kind == source.KindMCLID {
+			m.mclid = true
+			continue
+		}
+		hc := m.client.HTTPClient
+		if !m.trustedSource(spec) {
+			// saved before the checks existed, or the allowed hosts changed
+			if err := m.checkSource(spec); err != nil {
+				utils.Logger.Error("skipping source", "userID", m.userID, "source", spec.Name, "err", err)
+				continue
+			}
+			hc = m.publicHTTP
+		}
+		src, err := source.New(spec, m.client, hc)
+		if err != nil {
+			utils.Logger.Error("skipping source", "source", spec.Name, "err", err)
+			continue
+		}
+		m.extraSources = append(m.extraSources, src)
+	}
+	utils.Logger.Info("sources loaded", "userID", m.userID, "mclid", m.mclid, "extra", len(m.extraSources))
+}
+
+// trustedSource reports whether spec needs no checks: local users may read
+// anything, and SSH users may turn the config's enabled sources off and on
+func (m *model) trustedSource(spec types.SourceSpec) bool {
+	if m.userID == "local" {
+		return true
+	}
+	spec.Enabled = true
+	return slices.Contains(m.defaultSources, spec)
+}
+
+// checkSource validates a spec the user adds or enables
+func (m *model) checkSource(spec types.SourceSpec) error {
+	if m.userID == "local" {
+		return source.Validate(spec, false)
+	}
+	return source.CheckRemote(spec, m.sourceHosts)
+}
+
+// fetchSourcesCmd queries the non mcli.d providers
+func (m *model) fetchSourcesCmd() tea.Cmd {
+	if m.offline || len(m.extraSources) == 0 {
+		return nil
+	}
+	return source.FetchCmd(m.ctx, m.extraSources)
+}
+
+// mergeEvents combines mcli.d and provider events into the displayed list,
+// DisplayedEvents applies the time window
+func (m *model) mergeEvents() {
+	m.Events = api.SortEvents(source.Merge(m.baseEvents, m.extraEvents), m.zone)
+	// rows are rendered far more often than events change
+	m.located = make(map[types.EventId]location, len(m.Events))
+	for _, e := range m.Events {
+		var l location
+		l.p, l.exact, l.ok = geo.Locate(e.Location)
+		m.located[e.ID] = l
+	}
+}
+
+// location is where geo.Locate puts an event
+type location struct {
+	p     geo.Point
+	exact bool // geocoded, not the center of the venue's city
+	ok    bool
+}
+
+// handleSourceCommand implements :sources and :source add|rm|enable|disable
+func (m *model) handleSourceCommand(args []string) (string, tea.Cmd) {
+	const usage = "Usage: source add <kind> <name> [path|url] | rm <name> | enable <name> | disable <name>"
+	resolved := source.Resolve(m.defaultSources, m.profile.Sources)
+	if len(args) == 0 {
+		var list []string
+		for _, spec := range resolved {
+			state := "off"
+			if spec.Enabled {
+				state = "on"
+			}
+			list = append(list, fmt.Sprintf("%s (%s, %s)", spec.Name, spec.Kind, state))
+		}
+		if len(list) == 0 {
+			return "No sources configured", nil
+		}
+		return "Sources: " + strings.Join(list, ", "), nil
+	}
+	if len(args) < 2 {
+		return usage, nil
+	}
+
+	name := args[1]
+	var spec types.SourceSpec
+	switch strings.ToLower(args[0]) {
+	case "add":
+		if len(args) < 3 {
+			return usage, nil
+		}
+		spec = types.SourceSpec{Kind: args[1], Enabled: true}
+		spec.Name = args[2]
+		spec.Location = strings.Join(args[3:], " ")
+		if err := m.checkSource(spec); err != nil {
+			return err.Error(), nil
+		}
+		name = spec.Name
+	case "rm":
+		if !m.profile.RemoveSource(name) {
+			return fmt.Sprintf("No personal source named %s", name), nil
+		}
+		if err := m.store.RemoveSource(m.userID, name); err != nil {
+			utils.Logger.Error("failed to remove source", "err", err)
+			return "Failed to remove source", nil
+		}
+		m.extraEvents = nil
+		m.loadSources()
+		return fmt.Sprintf("Removed source %s", name), m.refreshCmd()
+	case "enable", "disable":
+		found := false
+		for _, s := range resolved {
+			if s.Name == name {
+				spec, found = s, true
+			}
+		}
+		if !found {
+			return fmt.Sprintf("No source named %s", name), nil
+		}
+		spec.Enabled = strings.ToLower(args[0]) == "enable"
+		// a config source the operator disabled may be one SSH users must not use
+		if !m.trustedSource(spec) {
+			if err := m.checkSource(spec); err != nil {
+				return err.Error(), nil
+			}
+		}
+	default:
+		return usage, nil
+	}
+
+	m.profile.SetSource(spec)
+	if err := m.store.SaveSource(m.userID, spec); err != nil {
+		utils.Logger.Error("failed to save source", "err", err)
+		return "Failed to save source", nil
+	}
+	m.extraEvents = nil
+	m.loadSources()
+	if !m.mclid {
+		m.baseEvents = nil
+	}
+	m.mergeEvents()
+	m.AdjustViewports()
+	return fmt.Sprintf("Source %s saved", name), m.refreshCmd()
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"mcli/internal/columns"
+	"mcli/internal/config"
+	"mcli/internal/tui"
+	"mcli/internal/types"
+	"mcli/internal/utils"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// toggleView switches to v, or back to the table when v is already shown
+func (m *model) toggleView(v viewMode) {
+	// the table has its own order, stay on the same event
+	selected, ok := m.selectedEvent()
+	if m.view == v {
+		m.view = viewTable
+	} else {
+		m.view = v
+	}
+	// only the visible view is kept up to date, fill this one
+	m.AdjustViewports()
+	if ok {
+		m.selectEvent(selected.ID)
+	}
+	if m.view == viewCalendar {
+		m.calendar.Select(m.table.Cursor())
+	}
+}
+
+// setSort orders the table by s and saves it in the profile
+func (m *model) setSort(s tui.Sort) string {
+	m.sort = s
+	m.profile.Sort = s.String()
+	if err := m.store.SaveSort(m.userID, m.profile.Sort); err != nil {
+		utils.Logger.Error("failed to save sort", "err", err)
+	}
+	m.table.SetSort(s)
+	m.table.GotoTop()
+	m.AdjustViewports()
+	if m.view != viewTable {
+		return fmt.Sprintf("Table sorted by %s, this view stays by date", s.Label())
+	}
+	return "Sorted by " + s.Label()
+}
+
+// columnsSetting is the table layout in effect: the profile's, else the config's
+func (m model) columnsSetting() string {
+	if m.profile.Columns != "" {
+		return m.profile.Columns
+	}
+	return m.defaultColumns
+}
+
+// handleColumnsCommand implements :columns [<name[:weight]>,...|reset]
+func (m *model) handleColumnsCommand(arg string) string {
+	switch arg {
+	case "":
+		return fmt.Sprintf("Columns: %s. Usage: columns <name[:weight]>,... or columns reset; available: %s",
+			columns.Format(m.table.Layout()), strings.Join(columns.Names, ", "))
+	case "reset":
+		arg = ""
+	}
+	layout, err := columns.Parse(arg)
+	if arg == "" {
+		layout, err = columns.Parse(m.defaultColumns)
+	}
+	if err != nil {
+		return err.Error()
+	}
+	if arg != "" {
+		arg = columns.Format(layout)
+	}
+	if err := m.store.SaveColumns(m.userID, arg); err != nil {
+		utils.Logger.Error("failed to save columns", "err", err)
+		return "Failed to save columns"
+	}
+	m.profile.Columns = arg
+	m.table.SetLayout(layout)
+	m.AdjustViewports()
+	return "Columns: " + columns.Format(layout)
+}
+
+// handleSortCommand implements :sort [<key> [asc|desc]|reverse]
+func (m *model) handleSortCommand(arg string) string {
+	switch arg {
+	case "":
+		return fmt.Sprintf("Sort: %s. Usage: sort %s [asc|desc], or sort reverse", m.sort.Label(), strings.Join(tui.SortKeys, "|"))
+	case "reverse":
+		return m.setSort(tui.Sort{Key: m.sort.Key, Desc: !m.sort.Desc})
+	}
+	s, err := tui.ParseSort(arg)
+	if err != nil {
+		return err.Error()
+	}
+	return m.setSort(s)
+}
+
+// selectedEvent returns the event under the cursor; in the calendar, the
+// selected event of the selected day, if it has any
+func (m model) selectedEvent() (types.Event, bool) {
+	events := m.DisplayedEvents()
+	i := m.table.Cursor()
+	if m.view == viewCalendar {
+		var ok bool
+		if i, ok = m.calendar.Selected(); !ok {
+			return types.Event{}, false
+		}
+	}
+	if i < 0 || i >= len(events) {
+		return types.Event{}, false
+	}
+	return events[i], true
+}
+
+// selectEvent moves the cursor to the displayed event with id, if any
+func (m *model) selectEvent(id types.EventId) {
+	for i, e := range m.DisplayedEvents() {
+		if e.ID == id {
+			m.table.SetCursor(i)
+			m.agenda.SetCursor(i)
+			return
+		}
+	}
+}
+
+// calendarKey moves through the month grid, reporting whether key was handled.
+// Keys bound to other actions are left to them.
+func (m *model) calendarKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	key := msg.String()
+	switch action := m.keys.CalendarAction(key); {
+	case action == config.ActionLeft || key == "left":
+		m.calendar.Move(-1)
+	case action == config.ActionRight || key == "right":
+		m.calendar.Move(1)
+	case action == config.ActionUp || key == "up":
+		m.calendar.Move(-7)
+	case action == config.ActionDown || key == "down":
+		m.calendar.Move(7)
+	case m.keys.Action(key) != "":
+		return false, nil
+	case key == "pgup" || key == "<":
+		m.calendar.MoveMonth(-1)
+	case key == "pgdown" || key == ">":
+		m.calendar.MoveMonth(1)
+	case key == "tab":
+		m.calendar.NextEvent(1)
+	case key == "shift+tab":
+		m.calendar.NextEvent(-1)
+	case key == "enter":
+		if _, ok := m.calendar.Selected(); !ok {
+			return true, nil
+		}
+		m.sidebar.ToggleSidebarView()
+		if event, ok := m.selectedEvent(); ok && m.sidebar.IsVisible() {
+			m.profile.MarkRead(event.ID)
+			m.store.AddReadEvent(m.userID, event.ID)
+		}
+	default:
+		return false, nil
+	}
+
+	// the table cursor follows so the other views resume on the same event
+	if i, ok := m.calendar.Selected(); ok {
+		m.table.SetCursor(i)
+		m.agenda.SetCursor(i)
+	}
+	if m.sidebar.IsVisible() {
+		m.sidebarMovement(nil)
+	}
+	return true, nil
+}
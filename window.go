@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"mcli/internal/api"
+	"mcli/internal/types"
+	"mcli/internal/utils"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// windowEvents returns the events inside the time window. Events before it
+// are kept when showPast is set; events with unparseable dates always are.
+func (m model) windowEvents() types.Events {
+	from, to := m.window.Bounds(time.Now(), m.zone)
+	var events types.Events
+	for _, e := range m.Events {
+		start, allDay, err := api.ParseDateTimeIn(e.DateTime, m.zone)
+		if err != nil {
+			events = append(events, e)
+			continue
+		}
+		if !to.IsZero() && !start.Before(to) {
+			continue
+		}
+		// all-day events last until the end of their day
+		past := start.Before(from)
+		if allDay {
+			past = !start.Add(24 * time.Hour).After(from)
+		}
+		if !past || m.showPast {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// setWindow switches the time window and saves it in the profile
+func (m *model) setWindow(w api.Window, showPast bool) string {
+	m.window, m.showPast = w, showPast
+	m.profile.Window, m.profile.ShowPast = w.String(), showPast
+	if err := m.store.SaveWindow(m.userID, m.profile.Window, showPast); err != nil {
+		utils.Logger.Error("failed to save window", "err", err)
+	}
+	m.statusbar.Window = m.windowStatus()
+	m.table.GotoTop()
+	m.AdjustViewports()
+
+	msg := "Showing " + w.Label()
+	if showPast {
+		msg += ", past events included"
+	}
+	return msg
+}
+
+// windowStatus is the status bar label, empty for the default upcoming window
+func (m model) windowStatus() string {
+	label := ""
+	if m.window.Preset != api.WindowUpcoming {
+		label = m.window.Label()
+	}
+	if m.showPast {
+		label = fmt.Sprintf("%s +past", label)
+	}
+	return strings.TrimSpace(label)
+}
+
+// windowRefreshCmd refetches when the loaded events may not cover the new
+// window; the pool holds every event, past ones too
+func (m *model) windowRefreshCmd() tea.Cmd {
+	if m.offline || m.pool != nil || !m.mclid {
+		return nil
+	}
+	return m.refreshCmd()
+}
+
+// handleWindowCommand implements :window [<preset>|<from>..<to>|past]
+func (m *model) handleWindowCommand(arg string) (string, tea.Cmd) {
+	switch arg {
+	case "":
+		return fmt.Sprintf("Window: %s. Usage: window upcoming|today|weekend|week|month|YYYY-MM-DD..YYYY-MM-DD|past", m.window.Label()), nil
+	case "past":
+		return m.setWindow(m.window, !m.showPast), m.windowRefreshCmd()
+	}
+	w, err := api.ParseWindow(arg)
+	if err != nil {
+		return err.Error(), nil
+	}
+	return m.setWindow(w, m.showPast), m.windowRefreshCmd()
+}